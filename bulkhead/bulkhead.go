@@ -0,0 +1,305 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package bulkhead provides the concurrency limit middleware of @bulkhead.
+// the bulkhead is kept per proxied method, or per the key extracted from the context.
+// the idle bulkhead is evicted, so the bulkheads of the short-lived keys do not accumulate.
+//
+//	type Foo interface {
+//		// @bulkhead(max=10, queue=50, wait=200ms)
+//		Fetch(c context.Context, id int) (dto.Foo, error)
+//	}
+//
+//	bh := bulkhead.New()
+//	m := map[string][]func(func(context.Context) error) func(context.Context) error{
+//		"bulkhead": {bh.Middleware()},
+//	}
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+const (
+	bulkheadAnnotation = "bulkhead"
+
+	maxArgument   = "max"
+	queueArgument = "queue"
+	waitArgument  = "wait"
+
+	defaultMax = 10
+
+	keySeparator = "/"
+
+	defaultIdleTimeout = 5 * time.Minute
+)
+
+var (
+	ErrBulkheadFull    = errors.New("bulkhead is full")
+	ErrInvalidBulkhead = errors.New("invalid bulkhead")
+)
+
+// Clock returns the current time. it is replaced to test deterministically.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Option configures the bulkheads.
+type Option func(*Bulkheads)
+
+// WithClock sets the clock that measures the idle time of the bulkheads. default is the system clock.
+func WithClock(clock Clock) Option {
+	return func(b *Bulkheads) {
+		b.clock = clock
+	}
+}
+
+// WithIdleTimeout sets the time after which the unused bulkhead is evicted. default is 5m.
+// the bulkhead that has the running or waiting call is not evicted.
+// the non-positive timeout keeps the bulkheads forever.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(b *Bulkheads) {
+		b.idleTimeout = timeout
+	}
+}
+
+// WithKey sets the function that extracts the key of the bulkhead from the context.
+// the bulkhead is kept per method and key. the empty key shares the bulkhead of the method.
+func WithKey(f func(c context.Context) string) Option {
+	return func(b *Bulkheads) {
+		b.key = f
+	}
+}
+
+// KeyFromContext keeps the bulkhead per the value of the context key. e.g. tenant ID
+func KeyFromContext(key any) Option {
+	return WithKey(func(c context.Context) string {
+		value := c.Value(key)
+		if value == nil {
+			return ""
+		}
+		return fmt.Sprint(value)
+	})
+}
+
+// settings is the bulkhead declared on the proxied method.
+type settings struct {
+	max   int
+	queue int
+	wait  time.Duration
+}
+
+func settingsFromInvocation(inv *invocation.Invocation) (settings, error) {
+	s := settings{
+		max: defaultMax,
+	}
+
+	var err error
+	if value, ok := inv.Argument(bulkheadAnnotation, maxArgument); ok && value != "" {
+		if s.max, err = strconv.Atoi(value); err != nil {
+			return settings{}, err
+		}
+	}
+
+	if value, ok := inv.Argument(bulkheadAnnotation, queueArgument); ok && value != "" {
+		if s.queue, err = strconv.Atoi(value); err != nil {
+			return settings{}, err
+		}
+	}
+
+	if value, ok := inv.Argument(bulkheadAnnotation, waitArgument); ok && value != "" {
+		if s.wait, err = time.ParseDuration(value); err != nil {
+			return settings{}, err
+		}
+	}
+
+	if s.max <= 0 || s.queue < 0 {
+		return settings{}, fmt.Errorf("%w. max must be positive and queue must not be negative on %s", ErrInvalidBulkhead, inv)
+	}
+	return s, nil
+}
+
+// bulkhead limits the concurrent calls.
+type bulkhead struct {
+	slots chan struct{}
+
+	mu      sync.Mutex
+	queue   int
+	waiting int
+
+	// refs counts the calls that use the bulkhead, and last is the time the last call returned.
+	// they are guarded by the mutex of Bulkheads.
+	refs int
+	last time.Time
+}
+
+// acquire takes the slot. the call waits in the queue for the slot up to wait.
+// the zero wait waits until the context is done.
+func (b *bulkhead) acquire(c context.Context, wait time.Duration) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	b.mu.Lock()
+	if b.waiting >= b.queue {
+		b.mu.Unlock()
+		return ErrBulkheadFull
+	}
+	b.waiting++
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.waiting--
+		b.mu.Unlock()
+	}()
+
+	var timeout <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return ErrBulkheadFull
+	case <-c.Done():
+		return errors.Join(ErrBulkheadFull, c.Err())
+	}
+}
+
+func (b *bulkhead) release() {
+	<-b.slots
+}
+
+// Bulkheads keeps the bulkheads of the proxied methods.
+type Bulkheads struct {
+	mu        sync.Mutex
+	bulkheads map[string]*bulkhead
+	key       func(c context.Context) string
+	clock     Clock
+
+	idleTimeout time.Duration
+	lastEvicted time.Time
+}
+
+// New returns the bulkheads.
+func New(opts ...Option) *Bulkheads {
+	b := &Bulkheads{
+		bulkheads:   map[string]*bulkhead{},
+		clock:       systemClock{},
+		idleTimeout: defaultIdleTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Middleware returns a middleware of @bulkhead.
+// the call is rejected with ErrBulkheadFull if the queue is full or the wait elapses.
+func (b *Bulkheads) Middleware() func(func(c context.Context) error) func(context.Context) error {
+	return func(next func(c context.Context) error) func(context.Context) error {
+		return func(c context.Context) error {
+			inv, _ := invocation.FromContext(c)
+			s, err := settingsFromInvocation(inv)
+			if err != nil {
+				return err
+			}
+
+			key := inv.FullName()
+			if b.key != nil {
+				if k := b.key(c); k != "" {
+					key += keySeparator + k
+				}
+			}
+
+			bh := b.get(key, s)
+			defer b.put(bh)
+
+			if err := bh.acquire(c, s.wait); err != nil {
+				return fmt.Errorf("%w on %s", err, key)
+			}
+			defer bh.release()
+			return next(c)
+		}
+	}
+}
+
+// get returns the bulkhead of the key and holds it until put.
+func (b *Bulkheads) get(key string, s settings) *bulkhead {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.evict(b.clock.Now())
+
+	bh, ok := b.bulkheads[key]
+	if !ok {
+		bh = &bulkhead{slots: make(chan struct{}, s.max), queue: s.queue}
+		b.bulkheads[key] = bh
+	}
+	bh.refs++
+	return bh
+}
+
+// put releases the bulkhead held by get.
+func (b *Bulkheads) put(bh *bulkhead) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bh.refs--
+	bh.last = b.clock.Now()
+}
+
+// evict removes the bulkheads unused for the idle timeout.
+// it scans the bulkheads at most once per the idle timeout.
+func (b *Bulkheads) evict(now time.Time) {
+	if b.idleTimeout <= 0 || now.Sub(b.lastEvicted) < b.idleTimeout {
+		return
+	}
+	b.lastEvicted = now
+
+	for key, bh := range b.bulkheads {
+		if bh.refs == 0 && now.Sub(bh.last) >= b.idleTimeout {
+			delete(b.bulkheads, key)
+		}
+	}
+}
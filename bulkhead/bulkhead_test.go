@@ -0,0 +1,222 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+// fakeClock is the clock advanced by the test.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// fetch returns the context of the call of Foo.Fetch annotated with @bulkhead(args...).
+// args are "key=value" pairs.
+func fetch(c context.Context, args ...string) context.Context {
+	arguments := []invocation.Argument{}
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, "=")
+		arguments = append(arguments, invocation.Argument{Key: key, Value: value})
+	}
+
+	return invocation.WithContext(c, &invocation.Invocation{
+		Package:   "example.com/service",
+		Interface: "Foo",
+		Method:    "Fetch",
+		Annotations: []invocation.Annotation{
+			{Name: bulkheadAnnotation, Arguments: arguments},
+		},
+	})
+}
+
+// blocked is the call blocked in the bulkhead until it is released.
+type blocked struct {
+	running chan struct{}
+	release chan struct{}
+	done    chan error
+}
+
+// block starts the call of the bulkhead that blocks until it is released.
+func block(b *Bulkheads, c context.Context) *blocked {
+	call := &blocked{
+		running: make(chan struct{}),
+		release: make(chan struct{}),
+		done:    make(chan error, 1),
+	}
+
+	go func() {
+		call.done <- b.Middleware()(func(context.Context) error {
+			close(call.running)
+			<-call.release
+			return nil
+		})(c)
+	}()
+	return call
+}
+
+// waiting returns the number of the calls waiting in the bulkhead of Foo.Fetch.
+func waiting(b *Bulkheads) int {
+	b.mu.Lock()
+	bh := b.bulkheads["example.com/service.Foo.Fetch"]
+	b.mu.Unlock()
+
+	bh.mu.Lock()
+	defer bh.mu.Unlock()
+	return bh.waiting
+}
+
+func waitFor(t *testing.T, f func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !f() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition is not satisfied")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func call(b *Bulkheads, c context.Context) error {
+	return b.Middleware()(func(context.Context) error { return nil })(c)
+}
+
+func TestBulkheadQueue(t *testing.T) {
+	b := New()
+	c := fetch(context.Background(), "max=1", "queue=1")
+
+	running := block(b, c)
+	<-running.running
+
+	// the second call waits in the queue, and the third is rejected
+	queued := block(b, c)
+	waitFor(t, func() bool { return waiting(b) == 1 })
+
+	if err := call(b, c); !errors.Is(err, ErrBulkheadFull) {
+		t.Fatalf("err = %v, want %v", err, ErrBulkheadFull)
+	}
+
+	// the queued call runs when the slot is released
+	close(running.release)
+	<-queued.running
+	close(queued.release)
+
+	for _, blocked := range []*blocked{running, queued} {
+		if err := <-blocked.done; err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+	}
+}
+
+func TestBulkheadWait(t *testing.T) {
+	b := New()
+	c := fetch(context.Background(), "max=1", "queue=1", "wait=20ms")
+
+	running := block(b, c)
+	<-running.running
+	defer close(running.release)
+
+	start := time.Now()
+	if err := call(b, c); !errors.Is(err, ErrBulkheadFull) {
+		t.Fatalf("err = %v, want %v", err, ErrBulkheadFull)
+	}
+
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("rejected after %v, want after wait 20ms", elapsed)
+	}
+}
+
+func TestBulkheadCanceled(t *testing.T) {
+	b := New()
+	running := block(b, fetch(context.Background(), "max=1", "queue=1"))
+	<-running.running
+	defer close(running.release)
+
+	c, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := call(b, fetch(c, "max=1", "queue=1"))
+	if !errors.Is(err, ErrBulkheadFull) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want %v and %v", err, ErrBulkheadFull, context.DeadlineExceeded)
+	}
+}
+
+func TestBulkheadInvalid(t *testing.T) {
+	b := New()
+	for _, args := range [][]string{{"max=0"}, {"queue=-1"}} {
+		if err := call(b, fetch(context.Background(), args...)); !errors.Is(err, ErrInvalidBulkhead) {
+			t.Errorf("err of %v = %v, want %v", args, err, ErrInvalidBulkhead)
+		}
+	}
+}
+
+func TestBulkheadIdleEviction(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := New(WithClock(clock), WithIdleTimeout(time.Minute))
+	c := fetch(context.Background(), "max=1")
+
+	running := block(b, c)
+	<-running.running
+
+	// the bulkhead that has the running call is not evicted
+	clock.Advance(time.Hour)
+	if err := call(b, fetch(context.Background(), "max=1", "wait=1ms")); !errors.Is(err, ErrBulkheadFull) {
+		t.Fatalf("err = %v, want %v", err, ErrBulkheadFull)
+	}
+
+	close(running.release)
+	if err := <-running.done; err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Minute)
+	b.mu.Lock()
+	b.evict(clock.Now())
+	n := len(b.bulkheads)
+	b.mu.Unlock()
+
+	if n != 0 {
+		t.Fatalf("bulkheads = %d, want 0", n)
+	}
+}
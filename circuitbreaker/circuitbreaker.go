@@ -0,0 +1,387 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package circuitbreaker provides the middleware of @circuitbreaker.
+// the breaker keeps the state per proxied method qualified by the package. e.g. example.com/service.Foo.Fetch
+//
+//	type Foo interface {
+//		// @circuitbreaker(failureRatio=0.5, minRequests=20, openFor=30s)
+//		Fetch(c context.Context, id int) (dto.Foo, error)
+//	}
+//
+//	breaker := circuitbreaker.New()
+//	m := map[string][]func(func(context.Context) error) func(context.Context) error{
+//		"circuitbreaker": {breaker.Middleware()},
+//	}
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+const (
+	circuitBreakerAnnotation = "circuitbreaker"
+
+	failureRatioArgument     = "failureRatio"
+	minRequestsArgument      = "minRequests"
+	openForArgument          = "openFor"
+	windowArgument           = "window"
+	halfOpenRequestsArgument = "halfOpenRequests"
+
+	defaultFailureRatio     = 0.5
+	defaultMinRequests      = 20
+	defaultOpenFor          = 30 * time.Second
+	defaultWindow           = 60 * time.Second
+	defaultHalfOpenRequests = 1
+)
+
+var (
+	ErrOpen            = errors.New("circuit breaker is open")
+	ErrTooManyRequests = errors.New("too many requests in half-open circuit breaker")
+)
+
+// State is the state of the circuit breaker.
+type State int
+
+const (
+	// StateClosed passes the calls and counts the failures.
+	StateClosed State = iota
+
+	// StateOpen fails the calls fast with ErrOpen until openFor elapses.
+	StateOpen
+
+	// StateHalfOpen passes the limited calls to probe the recovery.
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown state " + strconv.Itoa(int(s))
+	}
+}
+
+// Clock returns the current time. it is replaced to test deterministically.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// StateChange is the change of the state of the method.
+type StateChange struct {
+	// Name is the method qualified by the package. e.g. example.com/service.Foo.Fetch
+	Name string
+	From State
+	To   State
+	At   time.Time
+}
+
+// Option configures the circuit breaker.
+type Option func(*Breaker)
+
+// WithClock sets the clock of the breaker. default is the system clock.
+func WithClock(clock Clock) Option {
+	return func(b *Breaker) {
+		b.clock = clock
+	}
+}
+
+// OnStateChange sets the listener of the state changes.
+// the listener is called synchronously after the state changes.
+func OnStateChange(f func(change StateChange)) Option {
+	return func(b *Breaker) {
+		b.onStateChange = f
+	}
+}
+
+// IsFailure decides whether the error of the method counts as the failure.
+// by default, every error except context.Canceled is the failure.
+func IsFailure(f func(err error) bool) Option {
+	return func(b *Breaker) {
+		b.isFailure = f
+	}
+}
+
+// settings is the circuit breaker declared on the proxied method.
+type settings struct {
+	failureRatio     float64
+	minRequests      int
+	openFor          time.Duration
+	window           time.Duration
+	halfOpenRequests int
+}
+
+func settingsFromInvocation(inv *invocation.Invocation) (settings, error) {
+	s := settings{
+		failureRatio:     defaultFailureRatio,
+		minRequests:      defaultMinRequests,
+		openFor:          defaultOpenFor,
+		window:           defaultWindow,
+		halfOpenRequests: defaultHalfOpenRequests,
+	}
+
+	var err error
+	if value, ok := inv.Argument(circuitBreakerAnnotation, failureRatioArgument); ok && value != "" {
+		if s.failureRatio, err = strconv.ParseFloat(value, 64); err != nil {
+			return settings{}, err
+		}
+	}
+
+	if value, ok := inv.Argument(circuitBreakerAnnotation, minRequestsArgument); ok && value != "" {
+		if s.minRequests, err = strconv.Atoi(value); err != nil {
+			return settings{}, err
+		}
+	}
+
+	if value, ok := inv.Argument(circuitBreakerAnnotation, openForArgument); ok && value != "" {
+		if s.openFor, err = time.ParseDuration(value); err != nil {
+			return settings{}, err
+		}
+	}
+
+	if value, ok := inv.Argument(circuitBreakerAnnotation, windowArgument); ok && value != "" {
+		if s.window, err = time.ParseDuration(value); err != nil {
+			return settings{}, err
+		}
+	}
+
+	if value, ok := inv.Argument(circuitBreakerAnnotation, halfOpenRequestsArgument); ok && value != "" {
+		if s.halfOpenRequests, err = strconv.Atoi(value); err != nil {
+			return settings{}, err
+		}
+	}
+	return s, nil
+}
+
+// circuit is the state of the method.
+type circuit struct {
+	settings settings
+	state    State
+
+	// generation is increased on every state change and window,
+	// so the results of the calls of the previous generation are ignored.
+	generation uint64
+	expiry     time.Time
+
+	// requests counts the admitted calls, and failures and successes count the completed calls.
+	requests  int
+	failures  int
+	successes int
+}
+
+// Breaker keeps the circuits of the proxied methods.
+type Breaker struct {
+	mu            sync.Mutex
+	circuits      map[string]*circuit
+	clock         Clock
+	onStateChange func(change StateChange)
+	isFailure     func(err error) bool
+}
+
+// New returns a circuit breaker.
+func New(opts ...Option) *Breaker {
+	b := &Breaker{
+		circuits: map[string]*circuit{},
+		clock:    systemClock{},
+		isFailure: func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// State returns the state of the method. e.g. b.State("example.com/service.Foo.Fetch")
+func (b *Breaker) State(name string) State {
+	b.mu.Lock()
+	cb, ok := b.circuits[name]
+	if !ok {
+		b.mu.Unlock()
+		return StateClosed
+	}
+
+	change := b.refresh(name, cb, b.clock.Now())
+	state := cb.state
+	b.mu.Unlock()
+
+	b.notify(change)
+	return state
+}
+
+// Middleware returns a middleware of @circuitbreaker.
+func (b *Breaker) Middleware() func(func(c context.Context) error) func(context.Context) error {
+	return func(next func(c context.Context) error) func(context.Context) error {
+		return func(c context.Context) error {
+			inv, _ := invocation.FromContext(c)
+			s, err := settingsFromInvocation(inv)
+			if err != nil {
+				return err
+			}
+
+			name := inv.FullName()
+			generation, err := b.before(name, s)
+			if err != nil {
+				return err
+			}
+
+			err = next(c)
+			b.after(name, generation, err == nil || !b.isFailure(err))
+			return err
+		}
+	}
+}
+
+// before admits the call and returns the generation of the circuit.
+func (b *Breaker) before(name string, s settings) (uint64, error) {
+	b.mu.Lock()
+	cb, ok := b.circuits[name]
+	if !ok {
+		cb = &circuit{settings: s}
+		cb.expiry = b.clock.Now().Add(s.window)
+		b.circuits[name] = cb
+	}
+
+	change := b.refresh(name, cb, b.clock.Now())
+	generation, err := admit(cb)
+	b.mu.Unlock()
+
+	b.notify(change)
+	return generation, err
+}
+
+func admit(cb *circuit) (uint64, error) {
+	switch cb.state {
+	case StateOpen:
+		return 0, ErrOpen
+	case StateHalfOpen:
+		if cb.requests >= cb.settings.halfOpenRequests {
+			return 0, ErrTooManyRequests
+		}
+	}
+
+	cb.requests++
+	return cb.generation, nil
+}
+
+// after counts the result of the call.
+func (b *Breaker) after(name string, generation uint64, success bool) {
+	b.mu.Lock()
+	cb := b.circuits[name]
+	now := b.clock.Now()
+	changes := []*StateChange{b.refresh(name, cb, now)}
+	if generation == cb.generation {
+		changes = append(changes, b.count(name, cb, success, now))
+	}
+	b.mu.Unlock()
+
+	for _, change := range changes {
+		b.notify(change)
+	}
+}
+
+func (b *Breaker) count(name string, cb *circuit, success bool, now time.Time) *StateChange {
+	if success {
+		cb.successes++
+		if cb.state == StateHalfOpen && cb.successes >= cb.settings.halfOpenRequests {
+			return b.setState(name, cb, StateClosed, now)
+		}
+		return nil
+	}
+
+	cb.failures++
+	switch cb.state {
+	case StateHalfOpen:
+		return b.setState(name, cb, StateOpen, now)
+	case StateClosed:
+		// the calls in flight are not counted until they complete
+		completed := cb.failures + cb.successes
+		ratio := float64(cb.failures) / float64(completed)
+		if completed >= cb.settings.minRequests && ratio >= cb.settings.failureRatio {
+			return b.setState(name, cb, StateOpen, now)
+		}
+	}
+	return nil
+}
+
+// refresh moves the open circuit to half-open after openFor, and resets the counts
+// of the closed circuit after the window.
+func (b *Breaker) refresh(name string, cb *circuit, now time.Time) *StateChange {
+	if now.Before(cb.expiry) {
+		return nil
+	}
+
+	switch cb.state {
+	case StateOpen:
+		return b.setState(name, cb, StateHalfOpen, now)
+	case StateClosed:
+		b.reset(cb, now)
+	}
+	return nil
+}
+
+func (b *Breaker) setState(name string, cb *circuit, state State, now time.Time) *StateChange {
+	change := &StateChange{Name: name, From: cb.state, To: state, At: now}
+	cb.state = state
+	b.reset(cb, now)
+	return change
+}
+
+// reset starts the new generation of the circuit.
+func (b *Breaker) reset(cb *circuit, now time.Time) {
+	cb.generation++
+	cb.requests, cb.failures, cb.successes = 0, 0, 0
+
+	switch cb.state {
+	case StateClosed:
+		cb.expiry = now.Add(cb.settings.window)
+	case StateOpen:
+		cb.expiry = now.Add(cb.settings.openFor)
+	case StateHalfOpen:
+		cb.expiry = time.Time{}
+	}
+}
+
+func (b *Breaker) notify(change *StateChange) {
+	if change != nil && b.onStateChange != nil {
+		b.onStateChange(*change)
+	}
+}
@@ -0,0 +1,282 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+var errFailed = errors.New("failed")
+
+const fetchName = "example.com/service.Foo.Fetch"
+
+// fakeClock is the clock advanced by the test.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// fetch returns the context of the call of example.com/service.Foo.Fetch annotated with @circuitbreaker(args...).
+// args are "key=value" pairs.
+func fetch(pkg string, args ...string) context.Context {
+	arguments := []invocation.Argument{}
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, "=")
+		arguments = append(arguments, invocation.Argument{Key: key, Value: value})
+	}
+
+	return invocation.WithContext(context.Background(), &invocation.Invocation{
+		Package:   pkg,
+		Interface: "Foo",
+		Method:    "Fetch",
+		Annotations: []invocation.Annotation{
+			{Name: circuitBreakerAnnotation, Arguments: arguments},
+		},
+	})
+}
+
+func result(err error) func(context.Context) error {
+	return func(context.Context) error { return err }
+}
+
+func TestBreakerStateTransition(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	changes := []string{}
+	b := New(WithClock(clock), OnStateChange(func(change StateChange) {
+		changes = append(changes, fmt.Sprintf("%s %s -> %s", change.Name, change.From, change.To))
+	}))
+
+	c := fetch("example.com/service", "failureRatio=0.5", "minRequests=4", "openFor=10s", "halfOpenRequests=2")
+	call := func(err error) error {
+		return b.Middleware()(result(err))(c)
+	}
+
+	// closed. the circuit opens when the failures reach the ratio of min requests
+	for _, err := range []error{nil, errFailed, nil} {
+		if got := call(err); !errors.Is(got, err) || (err == nil && got != nil) {
+			t.Fatalf("call() = %v, want %v", got, err)
+		}
+	}
+
+	if state := b.State(fetchName); state != StateClosed {
+		t.Fatalf("state = %v, want closed before min requests", state)
+	}
+
+	_ = call(errFailed)
+	if state := b.State(fetchName); state != StateOpen {
+		t.Fatalf("state = %v, want open", state)
+	}
+
+	// open. the call fails fast until openFor elapses
+	if err := call(nil); !errors.Is(err, ErrOpen) {
+		t.Fatalf("call() = %v, want %v", err, ErrOpen)
+	}
+
+	clock.Advance(10*time.Second - time.Nanosecond)
+	if state := b.State(fetchName); state != StateOpen {
+		t.Fatalf("state = %v, want open before openFor", state)
+	}
+
+	// half-open. the failed probe opens the circuit again
+	clock.Advance(time.Nanosecond)
+	if state := b.State(fetchName); state != StateHalfOpen {
+		t.Fatalf("state = %v, want half-open", state)
+	}
+
+	if err := call(errFailed); !errors.Is(err, errFailed) {
+		t.Fatalf("call() = %v, want %v", err, errFailed)
+	}
+
+	if state := b.State(fetchName); state != StateOpen {
+		t.Fatalf("state = %v, want open after failed probe", state)
+	}
+
+	// half-open. the successful probes close the circuit
+	clock.Advance(10 * time.Second)
+	for range 2 {
+		if err := call(nil); err != nil {
+			t.Fatalf("call() = %v, want nil", err)
+		}
+	}
+
+	if state := b.State(fetchName); state != StateClosed {
+		t.Fatalf("state = %v, want closed", state)
+	}
+
+	want := []string{
+		fetchName + " closed -> open",
+		fetchName + " open -> half-open",
+		fetchName + " half-open -> open",
+		fetchName + " open -> half-open",
+		fetchName + " half-open -> closed",
+	}
+	if !slices.Equal(changes, want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+}
+
+func TestBreakerHalfOpenRequests(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := New(WithClock(clock))
+	c := fetch("example.com/service", "minRequests=1", "openFor=1s", "halfOpenRequests=1")
+
+	_ = b.Middleware()(result(errFailed))(c)
+	clock.Advance(time.Second)
+
+	// the probe in flight holds the only half-open request
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- b.Middleware()(func(context.Context) error {
+			close(probing)
+			<-release
+			return nil
+		})(c)
+	}()
+	<-probing
+
+	if err := b.Middleware()(result(nil))(c); !errors.Is(err, ErrTooManyRequests) {
+		t.Fatalf("call() = %v, want %v", err, ErrTooManyRequests)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("probe = %v, want nil", err)
+	}
+
+	if state := b.State(fetchName); state != StateClosed {
+		t.Fatalf("state = %v, want closed", state)
+	}
+}
+
+func TestBreakerRatioOfCompletedCalls(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := New(WithClock(clock))
+	c := fetch("example.com/service", "failureRatio=0.6", "minRequests=2")
+
+	// the calls in flight do not dilute the failure ratio
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 2 {
+		started := make(chan struct{})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = b.Middleware()(func(context.Context) error {
+				close(started)
+				<-release
+				return nil
+			})(c)
+		}()
+		<-started
+	}
+
+	for range 2 {
+		_ = b.Middleware()(result(errFailed))(c)
+	}
+
+	if state := b.State(fetchName); state != StateOpen {
+		t.Fatalf("state = %v, want open by 2 failures of 2 completed calls", state)
+	}
+
+	close(release)
+	wg.Wait()
+}
+
+func TestBreakerWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := New(WithClock(clock))
+	c := fetch("example.com/service", "failureRatio=0.5", "minRequests=2", "window=1m")
+
+	_ = b.Middleware()(result(errFailed))(c)
+
+	// the failure of the previous window is not counted
+	clock.Advance(time.Minute)
+	_ = b.Middleware()(result(errFailed))(c)
+	if state := b.State(fetchName); state != StateClosed {
+		t.Fatalf("state = %v, want closed", state)
+	}
+
+	_ = b.Middleware()(result(errFailed))(c)
+	if state := b.State(fetchName); state != StateOpen {
+		t.Fatalf("state = %v, want open", state)
+	}
+}
+
+func TestBreakerKeyedByPackage(t *testing.T) {
+	b := New()
+	args := []string{"minRequests=1"}
+
+	_ = b.Middleware()(result(errFailed))(fetch("example.com/service", args...))
+	if err := b.Middleware()(result(nil))(fetch("example.com/other", args...)); err != nil {
+		t.Fatalf("call() = %v, want nil for the method of the other package", err)
+	}
+
+	if state := b.State("example.com/other.Foo.Fetch"); state != StateClosed {
+		t.Fatalf("state = %v, want closed", state)
+	}
+
+	if state := b.State(fetchName); state != StateOpen {
+		t.Fatalf("state = %v, want open", state)
+	}
+}
+
+func TestBreakerIsFailure(t *testing.T) {
+	errIgnored := errors.New("ignored")
+	b := New(IsFailure(func(err error) bool { return !errors.Is(err, errIgnored) }))
+	c := fetch("example.com/service", "minRequests=1")
+
+	_ = b.Middleware()(result(errIgnored))(c)
+	if state := b.State(fetchName); state != StateClosed {
+		t.Fatalf("state = %v, want closed", state)
+	}
+
+	// by default, context.Canceled is not the failure
+	b = New()
+	_ = b.Middleware()(result(context.Canceled))(c)
+	if state := b.State(fetchName); state != StateClosed {
+		t.Fatalf("state = %v, want closed by canceled call", state)
+	}
+}
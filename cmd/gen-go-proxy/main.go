@@ -28,6 +28,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/ISSuh/gen-go-proxy/internal/config"
 	"github.com/ISSuh/gen-go-proxy/internal/option"
 	"github.com/ISSuh/gen-go-proxy/internal/parser"
 )
@@ -51,6 +52,61 @@ func main() {
 		panic(err)
 	}
 
+	// Load config and annotation schemas
+	cfg, err := config.Load(args.Config)
+	if err != nil {
+		panic(errors.Join(errGenFailed, err))
+	}
+
+	registry, err := cfg.Registry()
+	if err != nil {
+		panic(errors.Join(errGenFailed, err))
+	}
+
+	// Generate proxy files
+	var outPath, packgeName string
+	if len(args.From) != 0 {
+		outPath, packgeName = generateFromImport(args, registry, cfg.Rules)
+	} else {
+		outPath, packgeName = generateFromTarget(args, registry, cfg.Rules)
+	}
+
+	// Generate transaction middleware
+	if args.UseTxMiddleware {
+		outFileName := txMiddlewareFileName + sourceFileExtention
+		outFilePath := filepath.Join(outPath, outFileName)
+
+		tmpl := parser.Template{
+			Data: &parser.TemplateData{
+				PackageName: packgeName,
+			},
+		}
+
+		g := parser.NewGenerator()
+		if err := g.GenerateTxMiddleware(outFilePath, tmpl); err != nil {
+			panic(errors.Join(errGenFailed, err))
+		}
+
+		fmt.Printf("Generate proxy: generate transaction middleware. To %s\n", outPath)
+
+		// Generate transaction adapters
+		adapters, err := parser.SplitTxAdapters(args.TxAdapter)
+		if err != nil {
+			panic(errors.Join(errGenFailed, err))
+		}
+
+		for _, adapter := range adapters {
+			outFilePath := filepath.Join(outPath, fmt.Sprintf(parser.TxAdapterFileNameFormat, adapter))
+			if err := g.GenerateTxAdapter(outFilePath, adapter, tmpl); err != nil {
+				panic(errors.Join(errGenFailed, err))
+			}
+
+			fmt.Printf("Generate proxy: generate %s transaction adapter. To %s\n", adapter, outPath)
+		}
+	}
+}
+
+func generateFromTarget(args option.Arguments, registry *parser.Registry, rules parser.Rules) (string, string) {
 	// Get target files
 	targetDir, err := pathToAbsPath(args.Target)
 	if err != nil {
@@ -102,6 +158,10 @@ func main() {
 			ProxyPackageName:     args.Package,
 			InterfacePackageName: args.InterfacePackage.Name,
 			InterfacePackagePath: args.InterfacePackage.Path,
+			AnnotationSyntax:     parser.AnnotationSyntax(args.AnnotationSyntax),
+			Registry:             registry,
+			Rules:                rules,
+			ExtractInterface:     args.ExtractInterface,
 		}
 
 		tmpl, err := g.Parse(param)
@@ -124,24 +184,56 @@ func main() {
 		packgeName = tmpl.Data.PackageName
 	}
 
-	// Generate transaction middleware
-	if args.UseTxMiddleware {
-		outFileName := txMiddlewareFileName + sourceFileExtention
-		outFilePath := filepath.Join(outPath, outFileName)
+	return outPath, packgeName
+}
 
-		tmpl := parser.Template{
-			Data: &parser.TemplateData{
-				PackageName: packgeName,
-			},
+func generateFromImport(args option.Arguments, registry *parser.Registry, rules parser.Rules) (string, string) {
+	// Get output path
+	outPath, err := pathToAbsPath(args.Output)
+	if err != nil {
+		panic(errors.Join(errGenFailed, err))
+	}
+
+	packgeName := args.Package
+	if packgeName == "" {
+		packgeName = filepath.Base(outPath)
+	}
+
+	// Generate proxy files from interfaces of imported packages
+	for _, from := range args.From {
+		_, interfaceName, err := parser.SplitFrom(from)
+		if err != nil {
+			panic(errors.Join(errGenFailed, err))
 		}
 
+		outFileName := parser.ProxyFileName(interfaceName)
+		outFilePath := filepath.Join(outPath, outFileName)
+
+		// Load interface from package
 		g := parser.NewGenerator()
-		if err := g.GenerateTxMiddleware(outFilePath, tmpl); err != nil {
+
+		param := parser.FromParam{
+			From:             from,
+			OutFile:          outFileName,
+			ProxyPackageName: packgeName,
+			Registry:         registry,
+			Rules:            rules,
+		}
+
+		tmpl, err := g.ParseFrom(param)
+		if err != nil {
 			panic(errors.Join(errGenFailed, err))
 		}
 
-		fmt.Printf("Generate proxy: generate transaction middleware. To %s\n", outPath)
+		fmt.Printf("Generate proxy: from %s. to %s\n", from, outFilePath)
+
+		// Generate proxy file
+		if err := g.GenerateProxy(outFilePath, tmpl); err != nil {
+			panic(errors.Join(errGenFailed, err))
+		}
 	}
+
+	return outPath, packgeName
 }
 
 func pathToAbsPath(path string) (string, error) {
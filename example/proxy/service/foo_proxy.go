@@ -3,12 +3,16 @@
 
 package service
 
-import "context"
+import (
+	"context"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
 
 const (
 	proxyAnnotationKeyOnFoo   string = "proxy"
-	custom2AnnotationKeyOnFoo string = "custom2"
 	custom1AnnotationKeyOnFoo string = "custom1"
+	custom2AnnotationKeyOnFoo string = "custom2"
 )
 
 // helper for FooProxy middleware
@@ -40,8 +44,8 @@ func (a FooProxyMiddlewareByAnnotation) To() map[string][]func(func(context.Cont
 type FooProxy struct {
 	target             Foo
 	proxyMiddlewares   []func(func(context.Context) error) func(context.Context) error
-	custom2Middlewares []func(func(context.Context) error) func(context.Context) error
 	custom1Middlewares []func(func(context.Context) error) func(context.Context) error
+	custom2Middlewares []func(func(context.Context) error) func(context.Context) error
 }
 
 func NewFooProxy(target Foo, middlewares map[string][]func(func(context.Context) error) func(context.Context) error) *FooProxy {
@@ -54,16 +58,29 @@ func NewFooProxy(target Foo, middlewares map[string][]func(func(context.Context)
 
 		case proxyAnnotationKeyOnFoo:
 			p.proxyMiddlewares = value
-		case custom2AnnotationKeyOnFoo:
-			p.custom2Middlewares = value
 		case custom1AnnotationKeyOnFoo:
 			p.custom1Middlewares = value
+		case custom2AnnotationKeyOnFoo:
+			p.custom2Middlewares = value
 		}
 	}
 
 	return p
 }
 
+// invocation metadata of Foo.Logic for middlewares
+var fooProxyLogicInvocation = &invocation.Invocation{
+	Package:   "github.com/ISSuh/gen-go-proxy/example/proxy/service",
+	Interface: "Foo",
+	Method:    "Logic",
+	Annotations: []invocation.Annotation{
+		{
+			Name: "proxy",
+		},
+	},
+}
+
+// middleware order: @proxy
 func (p *FooProxy) Logic(needEmitErr bool) (string, error) {
 	var (
 		r0  string
@@ -83,10 +100,26 @@ func (p *FooProxy) Logic(needEmitErr bool) (string, error) {
 		f = p.proxyMiddlewares[index](f)
 	}
 
-	f(context.TODO())
+	err = f(invocation.WithContext(context.TODO(), fooProxyLogicInvocation))
 	return r0, err
 }
 
+// invocation metadata of Foo.Foo for middlewares
+var fooProxyFooInvocation = &invocation.Invocation{
+	Package:   "github.com/ISSuh/gen-go-proxy/example/proxy/service",
+	Interface: "Foo",
+	Method:    "Foo",
+	Annotations: []invocation.Annotation{
+		{
+			Name: "custom1",
+		},
+		{
+			Name: "custom2",
+		},
+	},
+}
+
+// middleware order: @custom1 -> @custom2
 func (p *FooProxy) Foo() int {
 	var (
 		r0 int
@@ -107,14 +140,14 @@ func (p *FooProxy) Foo() int {
 		f = p.custom1Middlewares[index](f)
 	}
 
-	f(context.TODO())
+	f(invocation.WithContext(context.TODO(), fooProxyFooInvocation))
 	return r0
 }
 
 const (
 	proxyAnnotationKeyOnBar   string = "proxy"
-	custom2AnnotationKeyOnBar string = "custom2"
 	custom1AnnotationKeyOnBar string = "custom1"
+	custom2AnnotationKeyOnBar string = "custom2"
 )
 
 // helper for BarProxy middleware
@@ -146,8 +179,8 @@ func (a BarProxyMiddlewareByAnnotation) To() map[string][]func(func(context.Cont
 type BarProxy struct {
 	target             Bar
 	proxyMiddlewares   []func(func(context.Context) error) func(context.Context) error
-	custom2Middlewares []func(func(context.Context) error) func(context.Context) error
 	custom1Middlewares []func(func(context.Context) error) func(context.Context) error
+	custom2Middlewares []func(func(context.Context) error) func(context.Context) error
 }
 
 func NewBarProxy(target Bar, middlewares map[string][]func(func(context.Context) error) func(context.Context) error) *BarProxy {
@@ -160,16 +193,29 @@ func NewBarProxy(target Bar, middlewares map[string][]func(func(context.Context)
 
 		case proxyAnnotationKeyOnBar:
 			p.proxyMiddlewares = value
-		case custom2AnnotationKeyOnBar:
-			p.custom2Middlewares = value
 		case custom1AnnotationKeyOnBar:
 			p.custom1Middlewares = value
+		case custom2AnnotationKeyOnBar:
+			p.custom2Middlewares = value
 		}
 	}
 
 	return p
 }
 
+// invocation metadata of Bar.Logic for middlewares
+var barProxyLogicInvocation = &invocation.Invocation{
+	Package:   "github.com/ISSuh/gen-go-proxy/example/proxy/service",
+	Interface: "Bar",
+	Method:    "Logic",
+	Annotations: []invocation.Annotation{
+		{
+			Name: "proxy",
+		},
+	},
+}
+
+// middleware order: @proxy
 func (p *BarProxy) Logic(needEmitErr bool) (string, error) {
 	var (
 		r0  string
@@ -189,10 +235,26 @@ func (p *BarProxy) Logic(needEmitErr bool) (string, error) {
 		f = p.proxyMiddlewares[index](f)
 	}
 
-	f(context.TODO())
+	err = f(invocation.WithContext(context.TODO(), barProxyLogicInvocation))
 	return r0, err
 }
 
+// invocation metadata of Bar.Foo for middlewares
+var barProxyFooInvocation = &invocation.Invocation{
+	Package:   "github.com/ISSuh/gen-go-proxy/example/proxy/service",
+	Interface: "Bar",
+	Method:    "Foo",
+	Annotations: []invocation.Annotation{
+		{
+			Name: "custom1",
+		},
+		{
+			Name: "custom2",
+		},
+	},
+}
+
+// middleware order: @custom1 -> @custom2
 func (p *BarProxy) Foo() int {
 	var (
 		r0 int
@@ -213,6 +275,6 @@ func (p *BarProxy) Foo() int {
 		f = p.custom1Middlewares[index](f)
 	}
 
-	f(context.TODO())
+	f(invocation.WithContext(context.TODO(), barProxyFooInvocation))
 	return r0
 }
@@ -95,11 +95,8 @@ func (s *Server) useSQL() error {
 		return err
 	}
 
-	// implement transaction factory function
-	txFatory := func() (proxy.Transaction, error) {
-		tx := infrsql.NewSQLTransaction(db)
-		return tx, nil
-	}
+	// transaction factory of the generated adapter
+	txFatory := proxy.NewSQLTransactionFactory(db)
 
 	// create transaction middleware
 	txMiddleware := proxy.TxMiddleware(txFatory)
@@ -130,11 +127,8 @@ func (s *Server) useGORM() error {
 		return err
 	}
 
-	// implement transaction factory function
-	txFatory := func() (proxy.Transaction, error) {
-		tx := infraorm.NewGORMTransaction(db)
-		return tx, nil
-	}
+	// transaction factory of the generated adapter
+	txFatory := proxy.NewGORMTransactionFactory(db)
 
 	// create transaction middleware
 	txMiddleware := proxy.TxMiddleware(txFatory)
@@ -27,6 +27,7 @@ import (
 	"errors"
 
 	"github.com/ISSuh/gen-go-proxy/example/transaction/entity"
+	"github.com/ISSuh/gen-go-proxy/example/transaction/service/proxy"
 	"gorm.io/gorm"
 )
 
@@ -41,11 +42,7 @@ func NewFooGORMRepository(db *gorm.DB) *FooGORMRepository {
 }
 
 func (r *FooGORMRepository) Create(c context.Context, value int) (int, error) {
-	conn, err := FromContext(c)
-	if err != nil {
-		return 0, err
-	}
-
+	conn := proxy.GORMFromContext(c, r.db)
 	if value < 0 {
 		return 0, errors.New("value must be greater than 0")
 	}
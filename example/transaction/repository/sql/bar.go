@@ -28,6 +28,7 @@ import (
 	"errors"
 
 	"github.com/ISSuh/gen-go-proxy/example/transaction/entity"
+	"github.com/ISSuh/gen-go-proxy/example/transaction/service/proxy"
 )
 
 type BarSQLRepository struct {
@@ -41,11 +42,7 @@ func NewBarSQLRepository(db *sql.DB) *BarSQLRepository {
 }
 
 func (r *BarSQLRepository) Create(c context.Context, value int) (int, error) {
-	tx, err := FromContext(c)
-	if err != nil {
-		return 0, err
-	}
-
+	tx := proxy.SQLFromContext(c, r.db)
 	if value < 0 {
 		return 0, errors.New("value must be greater than 0")
 	}
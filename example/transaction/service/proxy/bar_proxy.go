@@ -9,6 +9,7 @@ import (
 	"github.com/ISSuh/gen-go-proxy/example/transaction/dto"
 	entity "github.com/ISSuh/gen-go-proxy/example/transaction/entity"
 	service "github.com/ISSuh/gen-go-proxy/example/transaction/service"
+	"github.com/ISSuh/gen-go-proxy/invocation"
 )
 
 const (
@@ -62,6 +63,24 @@ func NewBarProxy(target service.Bar, middlewares map[string][]func(func(context.
 	return p
 }
 
+// invocation metadata of Bar.Create for middlewares
+var barProxyCreateInvocation = &invocation.Invocation{
+	Package:   "github.com/ISSuh/gen-go-proxy/example/transaction/service",
+	Interface: "Bar",
+	Method:    "Create",
+	Annotations: []invocation.Annotation{
+		{
+			Name: "transactional",
+			Arguments: []invocation.Argument{
+				{Key: "propagation", Value: "REQUIRED"},
+				{Key: "isolation", Value: "DEFAULT"},
+				{Key: "readOnly", Value: "false"},
+			},
+		},
+	},
+}
+
+// middleware order: @transactional
 func (p *BarProxy) Create(_userCtx context.Context, dto dto.Bar) (int, error) {
 	var (
 		r0  int
@@ -81,7 +100,7 @@ func (p *BarProxy) Create(_userCtx context.Context, dto dto.Bar) (int, error) {
 		f = p.transactionalMiddlewares[index](f)
 	}
 
-	f(_userCtx)
+	err = f(invocation.WithContext(_userCtx, barProxyCreateInvocation))
 	return r0, err
 }
 
@@ -9,6 +9,7 @@ import (
 	"github.com/ISSuh/gen-go-proxy/example/transaction/dto"
 	entity "github.com/ISSuh/gen-go-proxy/example/transaction/entity"
 	service "github.com/ISSuh/gen-go-proxy/example/transaction/service"
+	"github.com/ISSuh/gen-go-proxy/invocation"
 )
 
 const (
@@ -62,6 +63,24 @@ func NewFooBarProxy(target service.FooBar, middlewares map[string][]func(func(co
 	return p
 }
 
+// invocation metadata of FooBar.Create for middlewares
+var fooBarProxyCreateInvocation = &invocation.Invocation{
+	Package:   "github.com/ISSuh/gen-go-proxy/example/transaction/service",
+	Interface: "FooBar",
+	Method:    "Create",
+	Annotations: []invocation.Annotation{
+		{
+			Name: "transactional",
+			Arguments: []invocation.Argument{
+				{Key: "propagation", Value: "REQUIRED"},
+				{Key: "isolation", Value: "DEFAULT"},
+				{Key: "readOnly", Value: "false"},
+			},
+		},
+	},
+}
+
+// middleware order: @transactional
 func (p *FooBarProxy) Create(_userCtx context.Context, foo dto.Foo, bar dto.Bar) (int, int, error) {
 	var (
 		r0  int
@@ -82,7 +101,7 @@ func (p *FooBarProxy) Create(_userCtx context.Context, foo dto.Foo, bar dto.Bar)
 		f = p.transactionalMiddlewares[index](f)
 	}
 
-	f(_userCtx)
+	err = f(invocation.WithContext(_userCtx, fooBarProxyCreateInvocation))
 	return r0, r1, err
 }
 
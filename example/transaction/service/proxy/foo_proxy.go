@@ -9,6 +9,7 @@ import (
 	"github.com/ISSuh/gen-go-proxy/example/transaction/dto"
 	entity "github.com/ISSuh/gen-go-proxy/example/transaction/entity"
 	service "github.com/ISSuh/gen-go-proxy/example/transaction/service"
+	"github.com/ISSuh/gen-go-proxy/invocation"
 )
 
 const (
@@ -62,6 +63,24 @@ func NewFooProxy(target service.Foo, middlewares map[string][]func(func(context.
 	return p
 }
 
+// invocation metadata of Foo.Create for middlewares
+var fooProxyCreateInvocation = &invocation.Invocation{
+	Package:   "github.com/ISSuh/gen-go-proxy/example/transaction/service",
+	Interface: "Foo",
+	Method:    "Create",
+	Annotations: []invocation.Annotation{
+		{
+			Name: "transactional",
+			Arguments: []invocation.Argument{
+				{Key: "propagation", Value: "REQUIRED"},
+				{Key: "isolation", Value: "DEFAULT"},
+				{Key: "readOnly", Value: "false"},
+			},
+		},
+	},
+}
+
+// middleware order: @transactional
 func (p *FooProxy) Create(_userCtx context.Context, dto dto.Foo) (int, error) {
 	var (
 		r0  int
@@ -81,7 +100,7 @@ func (p *FooProxy) Create(_userCtx context.Context, dto dto.Foo) (int, error) {
 		f = p.transactionalMiddlewares[index](f)
 	}
 
-	f(_userCtx)
+	err = f(invocation.WithContext(_userCtx, fooProxyCreateInvocation))
 	return r0, err
 }
 
@@ -89,6 +108,24 @@ func (p *FooProxy) Find(_userCtx context.Context, id int) (*entity.Foo, error) {
 	return p.target.Find(_userCtx, id)
 }
 
+// invocation metadata of Foo.FooBara for middlewares
+var fooProxyFooBaraInvocation = &invocation.Invocation{
+	Package:   "github.com/ISSuh/gen-go-proxy/example/transaction/service",
+	Interface: "Foo",
+	Method:    "FooBara",
+	Annotations: []invocation.Annotation{
+		{
+			Name: "transactional",
+			Arguments: []invocation.Argument{
+				{Key: "propagation", Value: "REQUIRED"},
+				{Key: "isolation", Value: "DEFAULT"},
+				{Key: "readOnly", Value: "false"},
+			},
+		},
+	},
+}
+
+// middleware order: @transactional
 func (p *FooProxy) FooBara(_userCtx context.Context, dto dto.Foo) error {
 	var (
 		err error
@@ -107,7 +144,7 @@ func (p *FooProxy) FooBara(_userCtx context.Context, dto dto.Foo) error {
 		f = p.transactionalMiddlewares[index](f)
 	}
 
-	f(_userCtx)
+	err = f(invocation.WithContext(_userCtx, fooProxyFooBaraInvocation))
 	return err
 }
 
@@ -162,6 +199,24 @@ func NewFoo2Proxy(target service.Foo2, middlewares map[string][]func(func(contex
 	return p
 }
 
+// invocation metadata of Foo2.Create for middlewares
+var foo2ProxyCreateInvocation = &invocation.Invocation{
+	Package:   "github.com/ISSuh/gen-go-proxy/example/transaction/service",
+	Interface: "Foo2",
+	Method:    "Create",
+	Annotations: []invocation.Annotation{
+		{
+			Name: "transactional",
+			Arguments: []invocation.Argument{
+				{Key: "propagation", Value: "REQUIRED"},
+				{Key: "isolation", Value: "DEFAULT"},
+				{Key: "readOnly", Value: "false"},
+			},
+		},
+	},
+}
+
+// middleware order: @transactional
 func (p *Foo2Proxy) Create(_userCtx context.Context, dto dto.Foo) (int, error) {
 	var (
 		r0  int
@@ -181,7 +236,7 @@ func (p *Foo2Proxy) Create(_userCtx context.Context, dto dto.Foo) (int, error) {
 		f = p.transactionalMiddlewares[index](f)
 	}
 
-	f(_userCtx)
+	err = f(invocation.WithContext(_userCtx, foo2ProxyCreateInvocation))
 	return r0, err
 }
 
@@ -189,6 +244,24 @@ func (p *Foo2Proxy) Find(_userCtx context.Context, id int) (*entity.Foo, error)
 	return p.target.Find(_userCtx, id)
 }
 
+// invocation metadata of Foo2.FooBara for middlewares
+var foo2ProxyFooBaraInvocation = &invocation.Invocation{
+	Package:   "github.com/ISSuh/gen-go-proxy/example/transaction/service",
+	Interface: "Foo2",
+	Method:    "FooBara",
+	Annotations: []invocation.Annotation{
+		{
+			Name: "transactional",
+			Arguments: []invocation.Argument{
+				{Key: "propagation", Value: "REQUIRED"},
+				{Key: "isolation", Value: "DEFAULT"},
+				{Key: "readOnly", Value: "false"},
+			},
+		},
+	},
+}
+
+// middleware order: @transactional
 func (p *Foo2Proxy) FooBara(_userCtx context.Context, dto dto.Foo) error {
 	var (
 		err error
@@ -207,6 +280,6 @@ func (p *Foo2Proxy) FooBara(_userCtx context.Context, dto dto.Foo) error {
 		f = p.transactionalMiddlewares[index](f)
 	}
 
-	f(_userCtx)
+	err = f(invocation.WithContext(_userCtx, foo2ProxyFooBaraInvocation))
 	return err
 }
@@ -4,19 +4,211 @@ package proxy
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
 )
 
 var (
-	ErrNilTransactionFactory = errors.New("transaction factory is nil")
-	ErrRollbackTransaction   = errors.New("rollback transaction")
+	ErrNilTransactionFactory         = errors.New("transaction factory is nil")
+	ErrRollbackTransaction           = errors.New("rollback transaction")
+	ErrNoExistingTransaction         = errors.New("no existing transaction found for propagation MANDATORY")
+	ErrExistingTransaction           = errors.New("existing transaction found for propagation NEVER")
+	ErrNestedTransactionNotSupported = errors.New("nested transaction is not supported by the transaction")
+	ErrSuspendNotSupported           = errors.New("suspending transaction is not supported by the transaction")
+	ErrUnknownPropagation            = errors.New("unknown propagation")
+	ErrUnknownIsolation              = errors.New("unknown isolation level")
+	ErrUnregisteredSentinelError     = errors.New("unregistered sentinel error")
+	ErrNoTransaction                 = errors.New("no transaction found")
+	ErrUnknownTransactionManager     = errors.New("unknown transaction manager")
+	ErrUnexpectedRollback            = errors.New("transaction rolled back because it has been marked as rollback-only")
+	ErrNoOutbox                      = errors.New("outbox store is not configured")
+)
+
+// TxOp is the operation of the transaction.
+type TxOp string
+
+const (
+	TxOpBegin    TxOp = "begin"
+	TxOpCommit   TxOp = "commit"
+	TxOpRollback TxOp = "rollback"
+)
+
+// TxError is the error of the failed transaction operation.
+// Cause is the error of the operation and Err is the error of the method
+// that caused the rollback. both are matched by errors.Is and errors.As.
+//
+//	var txErr *proxy.TxError
+//	if errors.As(err, &txErr) && txErr.Op == proxy.TxOpCommit {
+//		// the work of the method is not committed
+//	}
+type TxError struct {
+	Op    TxOp
+	Cause error
+	Err   error
+}
+
+func (e *TxError) Error() string {
+	message := "failed to " + string(e.Op) + " transaction: " + e.Cause.Error()
+	if e.Err != nil {
+		message += ". caused by: " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *TxError) Unwrap() []error {
+	errs := []error{e.Cause}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+const (
+	transactionalAnnotation = "transactional"
+	propagationArgument     = "propagation"
+	isolationArgument       = "isolation"
+	readOnlyArgument        = "readOnly"
+	timeoutArgument         = "timeout"
+	rollbackForArgument     = "rollbackFor"
+	noRollbackForArgument   = "noRollbackFor"
+	managerArgument         = "manager"
+	retryArgument           = "retry"
+	backoffArgument         = "backoff"
+	argumentValueSeparator  = "|"
+	savepointNamePrefix     = "gen_go_proxy_sp_"
+)
+
+// txScopeKey is the context key of the transaction scope of the manager.
+type txScopeKey struct {
+	manager string
+}
+
+// Propagation decides how the transactional method runs with the existing transaction.
+// it is declared on the method by the transactional annotation.
+//
+//	// @transactional(propagation=REQUIRES_NEW)
+//	Write(c context.Context, log AuditLog) error
+type Propagation string
+
+const (
+	// PropagationRequired joins the existing transaction or begins a new one. default.
+	PropagationRequired Propagation = "REQUIRED"
+
+	// PropagationRequiresNew always begins a new transaction and suspends the existing one.
+	// the new transaction commits or rolls back independently of the existing one.
+	PropagationRequiresNew Propagation = "REQUIRES_NEW"
+
+	// PropagationNested runs in the savepoint of the existing transaction.
+	// it begins a new transaction if no transaction exists.
+	PropagationNested Propagation = "NESTED"
+
+	// PropagationSupports joins the existing transaction or runs without transaction.
+	PropagationSupports Propagation = "SUPPORTS"
+
+	// PropagationMandatory joins the existing transaction or fails if no transaction exists.
+	PropagationMandatory Propagation = "MANDATORY"
+
+	// PropagationNotSupported runs without transaction and suspends the existing one.
+	PropagationNotSupported Propagation = "NOT_SUPPORTED"
+
+	// PropagationNever runs without transaction or fails if a transaction exists.
+	PropagationNever Propagation = "NEVER"
 )
 
+// propagationFromContext returns the propagation declared on the proxied method.
+func propagationFromContext(c context.Context) Propagation {
+	inv, _ := invocation.FromContext(c)
+	value, ok := inv.Argument(transactionalAnnotation, propagationArgument)
+	if !ok || value == "" {
+		return PropagationRequired
+	}
+	return Propagation(strings.ToUpper(value))
+}
+
+// TxOptions is the options to begin the transaction.
+// it is declared on the method by the transactional annotation.
+//
+//	// @transactional(isolation=SERIALIZABLE, readOnly=true, timeout=2s)
+//	Find(c context.Context, id int) (*entity.Foo, error)
+type TxOptions struct {
+	// Isolation is the isolation level of the transaction.
+	Isolation sql.IsolationLevel
+
+	// ReadOnly is true if the transaction is read-only.
+	ReadOnly bool
+
+	// Timeout is the timeout of the transaction. zero means no timeout.
+	// the transaction is rolled back if the timeout expires.
+	Timeout time.Duration
+}
+
+// SQL returns the options as sql.TxOptions.
+func (o TxOptions) SQL() *sql.TxOptions {
+	return &sql.TxOptions{
+		Isolation: o.Isolation,
+		ReadOnly:  o.ReadOnly,
+	}
+}
+
+var isolationLevels = map[string]sql.IsolationLevel{
+	"DEFAULT":          sql.LevelDefault,
+	"READ_UNCOMMITTED": sql.LevelReadUncommitted,
+	"READ_COMMITTED":   sql.LevelReadCommitted,
+	"WRITE_COMMITTED":  sql.LevelWriteCommitted,
+	"REPEATABLE_READ":  sql.LevelRepeatableRead,
+	"SNAPSHOT":         sql.LevelSnapshot,
+	"SERIALIZABLE":     sql.LevelSerializable,
+	"LINEARIZABLE":     sql.LevelLinearizable,
+}
+
+// txOptionsFromContext returns the transaction options declared on the proxied method.
+func txOptionsFromContext(c context.Context) (TxOptions, error) {
+	inv, _ := invocation.FromContext(c)
+	opts := TxOptions{}
+	if value, ok := inv.Argument(transactionalAnnotation, isolationArgument); ok && value != "" {
+		level, ok := isolationLevels[strings.ToUpper(value)]
+		if !ok {
+			return TxOptions{}, fmt.Errorf("%w %s", ErrUnknownIsolation, value)
+		}
+		opts.Isolation = level
+	}
+
+	if value, ok := inv.Argument(transactionalAnnotation, readOnlyArgument); ok && value != "" {
+		readOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			return TxOptions{}, err
+		}
+		opts.ReadOnly = readOnly
+	}
+
+	if value, ok := inv.Argument(transactionalAnnotation, timeoutArgument); ok && value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return TxOptions{}, err
+		}
+		opts.Timeout = timeout
+	}
+	return opts, nil
+}
+
 // Transaction is an interface that defines the methods to manage transactions.
 // User should implement this interface to manage transactions.
 type Transaction interface {
-	// Begin begins the transaction.
-	Begin() error
+	// Begin begins the transaction with the options.
+	// the transaction should be bound to the context. e.g. sql.DB.BeginTx
+	Begin(c context.Context, opts TxOptions) error
 
 	// Commit commits the transaction.
 	Commit() error
@@ -31,6 +223,28 @@ type Transaction interface {
 	From(c context.Context) error
 }
 
+// TransactionSuspender is an optional interface of the transaction to suspend the existing transaction.
+// it is required by propagation NOT_SUPPORTED when a transaction exists.
+type TransactionSuspender interface {
+	// Suspend returns a context without the transaction.
+	Suspend(c context.Context) context.Context
+}
+
+// SavepointTransaction is an optional interface of the transaction to support the nested transaction.
+// if the transaction implements it, the nested call of the transactional method runs in the savepoint
+// and only the work of the nested call is rolled back when it fails.
+// it is required by propagation NESTED when a transaction exists.
+type SavepointTransaction interface {
+	// Savepoint creates the savepoint.
+	Savepoint(name string) error
+
+	// RollbackTo rolls back the transaction to the savepoint.
+	RollbackTo(name string) error
+
+	// Release releases the savepoint.
+	Release(name string) error
+}
+
 // NewTransaction is a function that creates a new transaction.
 // User should implement this function to create a new transaction.
 //
@@ -45,9 +259,499 @@ type Transaction interface {
 // The function should return a new transaction and an error.
 type TransactionFactory func() (Transaction, error)
 
+// TxStatus is the status of the transaction of the transactional method.
+type TxStatus struct {
+	// IsActive is true if the method runs in the transaction.
+	IsActive bool
+
+	// IsNewTransaction is true if the method began the transaction.
+	IsNewTransaction bool
+
+	// IsRollbackOnly is true if the transaction is marked as rollback-only.
+	IsRollbackOnly bool
+
+	// Depth is the nested depth of the transactional method in the transaction.
+	// the method that began the transaction is 1.
+	Depth int
+}
+
+// txState is the state shared by the methods that run in the same transaction or savepoint.
+type txState struct {
+	rollbackOnly atomic.Bool
+}
+
+// txScope is the scope of the transactional method call.
+type txScope struct {
+	state *txState
+	syncs *txSynchronizations
+	isNew bool
+	depth int
+
+	// rollbackOnly is true if the method of the scope marked the transaction as rollback-only.
+	rollbackOnly atomic.Bool
+}
+
+func withTxScope(c context.Context, manager string, scope *txScope) context.Context {
+	return context.WithValue(c, txScopeKey{manager: manager}, scope)
+}
+
+func txScopeFromContext(c context.Context, manager string) (*txScope, bool) {
+	scope, ok := c.Value(txScopeKey{manager: manager}).(*txScope)
+	return scope, ok && scope != nil && scope.state != nil
+}
+
+// managerFromContext returns the first transaction manager declared on the proxied method.
+func managerFromContext(c context.Context) string {
+	return managerNamesFromContext(c)[0]
+}
+
+// managerNamesFromContext returns the transaction managers declared on the proxied method.
+// the default manager is returned if no manager is declared.
+func managerNamesFromContext(c context.Context) []string {
+	inv, _ := invocation.FromContext(c)
+	value, ok := inv.Argument(transactionalAnnotation, managerArgument)
+	if !ok || value == "" {
+		return []string{DefaultTransactionManager}
+	}
+
+	names := []string{}
+	for _, name := range strings.Split(value, argumentValueSeparator) {
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
+
+// TxStatusFromContext returns the status of the transaction of the transactional method.
+// the status is of the transaction manager declared on the method.
+//
+//	status := proxy.TxStatusFromContext(c)
+//	if status.IsNewTransaction {
+//		// the method began the transaction
+//	}
+func TxStatusFromContext(c context.Context) TxStatus {
+	return TxStatusOf(c, managerFromContext(c))
+}
+
+// TxStatusOf returns the status of the transaction of the transaction manager.
+func TxStatusOf(c context.Context, manager string) TxStatus {
+	scope, ok := txScopeFromContext(c, manager)
+	if !ok {
+		return TxStatus{}
+	}
+
+	return TxStatus{
+		IsActive:         true,
+		IsNewTransaction: scope.isNew,
+		IsRollbackOnly:   scope.state.rollbackOnly.Load(),
+		Depth:            scope.depth,
+	}
+}
+
+// SetRollbackOnly marks the transaction as rollback-only.
+// the transaction is rolled back instead of committed when the method that began it returns.
+// if the transaction is marked by the nested method, ErrUnexpectedRollback is returned to the caller
+// of the method that began it.
+func SetRollbackOnly(c context.Context) error {
+	return SetRollbackOnlyOf(c, managerFromContext(c))
+}
+
+// SetRollbackOnlyOf marks the transaction of the transaction manager as rollback-only.
+func SetRollbackOnlyOf(c context.Context, manager string) error {
+	scope, ok := txScopeFromContext(c, manager)
+	if !ok {
+		return ErrNoTransaction
+	}
+
+	scope.rollbackOnly.Store(true)
+	scope.state.rollbackOnly.Store(true)
+	return nil
+}
+
+// Synchronization is the callbacks invoked on the completion of the transaction.
+// the callbacks registered in the nested method are invoked by the method that began the transaction.
+// the callbacks registered in the savepoint join the transaction when the savepoint is released.
+// if the savepoint is rolled back, they are discarded after AfterRollback and AfterCompletion are invoked.
+type Synchronization struct {
+	// BeforeCommit is invoked with the transaction context before the commit.
+	// if it returns an error, the transaction is rolled back.
+	BeforeCommit func(c context.Context) error
+
+	// AfterCommit is invoked after the transaction is committed.
+	AfterCommit func(c context.Context)
+
+	// AfterRollback is invoked after the transaction is rolled back or failed to commit.
+	AfterRollback func(c context.Context)
+
+	// AfterCompletion is invoked after AfterCommit or AfterRollback.
+	AfterCompletion func(c context.Context, committed bool)
+}
+
+// txSynchronizations is the synchronizations registered in the transaction.
+type txSynchronizations struct {
+	mu    sync.Mutex
+	syncs []Synchronization
+}
+
+func (s *txSynchronizations) register(sync Synchronization) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncs = append(s.syncs, sync)
+}
+
+// join appends the synchronizations of the released savepoint.
+func (s *txSynchronizations) join(other *txSynchronizations) {
+	if s == nil || other == nil {
+		return
+	}
+
+	for _, sync := range other.list() {
+		s.register(sync)
+	}
+}
+
+func (s *txSynchronizations) list() []Synchronization {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.syncs)
+}
+
+func (s *txSynchronizations) beforeCommit(c context.Context) error {
+	for _, sync := range s.list() {
+		if sync.BeforeCommit == nil {
+			continue
+		}
+
+		if err := sync.BeforeCommit(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *txSynchronizations) afterCompletion(c context.Context, committed bool) {
+	for _, sync := range s.list() {
+		switch {
+		case committed && sync.AfterCommit != nil:
+			sync.AfterCommit(c)
+		case !committed && sync.AfterRollback != nil:
+			sync.AfterRollback(c)
+		}
+
+		if sync.AfterCompletion != nil {
+			sync.AfterCompletion(c, committed)
+		}
+	}
+}
+
+// RegisterSynchronization registers the callbacks invoked on the completion of the transaction.
+// the callbacks after the completion are invoked with the context out of the transaction.
+//
+//	proxy.RegisterSynchronization(c, proxy.Synchronization{
+//		AfterCommit: func(c context.Context) {
+//			mailer.Send(c, welcome)
+//		},
+//	})
+func RegisterSynchronization(c context.Context, sync Synchronization) error {
+	return RegisterSynchronizationOf(c, managerFromContext(c), sync)
+}
+
+// RegisterSynchronizationOf registers the callbacks invoked on the completion of the transaction
+// of the transaction manager.
+func RegisterSynchronizationOf(c context.Context, manager string, sync Synchronization) error {
+	scope, ok := txScopeFromContext(c, manager)
+	if !ok || scope.syncs == nil {
+		return ErrNoTransaction
+	}
+
+	scope.syncs.register(sync)
+	return nil
+}
+
+// TxOption configures the transaction middleware.
+type TxOption func(*txConfig)
+
+type txConfig struct {
+	rollbackOn     func(err error) bool
+	sentinelErrors map[string]error
+	managers       map[string]TransactionFactory
+	isRetryable    func(err error) bool
+	outbox         OutboxStore
+	router         DataSourceRouter
+	listeners      txListeners
+}
+
+// DefaultTransactionManager is the name of the transaction manager of the factory
+// passed to TxMiddlewareWithOptions. it is used if no manager is declared on the method.
+const DefaultTransactionManager = "default"
+
+// TransactionManager registers the transaction factory by name.
+// the name is referenced by manager of the annotation. the transaction of each manager
+// is stored in the separate context slot, so the method can run in the transactions
+// of the multiple managers.
+//
+//	// @transactional(manager=ledger)
+//	Record(c context.Context, entry dto.Entry) error
+//
+//	// @transactional(manager=orders|ledger)
+//	Settle(c context.Context, order dto.Order) error
+func TransactionManager(name string, factory TransactionFactory) TxOption {
+	return func(c *txConfig) {
+		c.managers[name] = factory
+	}
+}
+
+// Retryable decides whether the failed transaction is re-run by retry of the annotation.
+// by default, IsRetryable is used.
+//
+//	proxy.Retryable(func(err error) bool {
+//		return proxy.IsRetryable(err) || errors.Is(err, service.ErrConflict)
+//	})
+func Retryable(f func(err error) bool) TxOption {
+	return func(c *txConfig) {
+		c.isRetryable = f
+	}
+}
+
+// WithOutbox configures the outbox store used by Outbox in the transactional method.
+func WithOutbox(store OutboxStore) TxOption {
+	return func(c *txConfig) {
+		c.outbox = store
+	}
+}
+
+// WithDataSourceRouter configures the router of the read-only transaction.
+// the transaction of readOnly=true begins on the replica of the data source.
+func WithDataSourceRouter(router DataSourceRouter) TxOption {
+	return func(c *txConfig) {
+		c.router = router
+	}
+}
+
+// WithTxListener registers the listeners of the transaction lifecycle events.
+//
+//	txMiddleware := proxy.TxMiddlewareWithOptions(txFactory,
+//		proxy.WithTxListener(proxy.NewSlogTxListener(logger), proxy.NewExpvarTxListener("tx")),
+//	)
+func WithTxListener(listeners ...TxListener) TxOption {
+	return func(c *txConfig) {
+		c.listeners = append(c.listeners, listeners...)
+	}
+}
+
+// RollbackOn decides whether the error of the method rolls back the transaction.
+// by default, every error rolls back the transaction.
+// rollbackFor and noRollbackFor of the annotation take precedence over it.
+func RollbackOn(f func(err error) bool) TxOption {
+	return func(c *txConfig) {
+		c.rollbackOn = f
+	}
+}
+
+// SentinelErrors registers the sentinel errors by name.
+// the names are referenced by rollbackFor and noRollbackFor of the annotation.
+//
+//	// @transactional(noRollbackFor=ErrAlreadyExists)
+//	Create(c context.Context, dto dto.Foo) (int, error)
+func SentinelErrors(errs map[string]error) TxOption {
+	return func(c *txConfig) {
+		for name, err := range errs {
+			c.sentinelErrors[name] = err
+		}
+	}
+}
+
+// txCall is the call of the transactional method on the transaction manager.
+type txCall struct {
+	manager  string
+	rule     rollbackRule
+	retry    retryPolicy
+	listener txListeners
+}
+
+// event returns the lifecycle event of the transaction of the call started at start.
+func (t txCall) event(c context.Context, depth int, start time.Time, err error) TxEvent {
+	inv, _ := invocation.FromContext(c)
+	return TxEvent{
+		Manager:  t.manager,
+		Method:   inv.String(),
+		Depth:    depth,
+		Duration: time.Since(start),
+		Err:      err,
+	}
+}
+
+// rollbackRule decides whether the error of the method rolls back the transaction.
+type rollbackRule struct {
+	rollbackFor   []error
+	noRollbackFor []error
+	rollbackOn    func(err error) bool
+}
+
+func (r rollbackRule) shouldRollback(err error) bool {
+	for _, target := range r.noRollbackFor {
+		if errors.Is(err, target) {
+			return false
+		}
+	}
+
+	for _, target := range r.rollbackFor {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	if r.rollbackOn != nil {
+		return r.rollbackOn(err)
+	}
+	return true
+}
+
+// retryPolicy is the retry of the failed transaction declared on the proxied method.
+type retryPolicy struct {
+	max         int
+	backoff     time.Duration
+	isRetryable func(err error) bool
+}
+
+// retryPolicyFromContext returns the retry policy declared on the proxied method.
+func retryPolicyFromContext(c context.Context, config txConfig) (retryPolicy, error) {
+	policy := retryPolicy{
+		isRetryable: config.isRetryable,
+	}
+
+	inv, _ := invocation.FromContext(c)
+	if value, ok := inv.Argument(transactionalAnnotation, retryArgument); ok && value != "" {
+		retry, err := strconv.Atoi(value)
+		if err != nil {
+			return retryPolicy{}, err
+		}
+		policy.max = retry
+	}
+
+	if value, ok := inv.Argument(transactionalAnnotation, backoffArgument); ok && value != "" {
+		backoff, err := time.ParseDuration(value)
+		if err != nil {
+			return retryPolicy{}, err
+		}
+		policy.backoff = backoff
+	}
+
+	if policy.isRetryable == nil {
+		policy.isRetryable = IsRetryable
+	}
+	return policy, nil
+}
+
+const (
+	// postgres serialization_failure and deadlock_detected
+	postgresSerializationFailure = "40001"
+	postgresDeadlockDetected     = "40P01"
+
+	// mysql ER_LOCK_DEADLOCK and ER_LOCK_WAIT_TIMEOUT
+	mysqlLockDeadlock    = 1213
+	mysqlLockWaitTimeout = 1205
+)
+
+// IsRetryable reports whether the transaction failed by the serialization failure or the deadlock
+// of postgres or mysql. it is the default classifier of the retry.
+func IsRetryable(err error) bool {
+	return IsPostgresRetryable(err) || IsMySQLRetryable(err)
+}
+
+// IsPostgresRetryable reports whether the error is the serialization failure or the deadlock of postgres.
+// the errors that have SQLState, e.g. *pgconn.PgError of pgx and *pq.Error of lib/pq, are checked.
+func IsPostgresRetryable(err error) bool {
+	return matchError(err, func(err error) bool {
+		state, ok := err.(interface{ SQLState() string })
+		if !ok {
+			return false
+		}
+
+		code := state.SQLState()
+		return code == postgresSerializationFailure || code == postgresDeadlockDetected
+	})
+}
+
+// IsMySQLRetryable reports whether the error is the deadlock or the lock wait timeout of mysql.
+// *mysql.MySQLError of go-sql-driver/mysql is checked by its Number.
+func IsMySQLRetryable(err error) bool {
+	return matchError(err, func(err error) bool {
+		v := reflect.ValueOf(err)
+		if v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+
+		if v.Kind() != reflect.Struct || v.Type().Name() != "MySQLError" {
+			return false
+		}
+
+		number := v.FieldByName("Number")
+		if !number.IsValid() || !number.CanUint() {
+			return false
+		}
+		return number.Uint() == mysqlLockDeadlock || number.Uint() == mysqlLockWaitTimeout
+	})
+}
+
+// matchError reports whether any error in the tree of err matches.
+func matchError(err error, match func(err error) bool) bool {
+	if err == nil {
+		return false
+	}
+
+	if match(err) {
+		return true
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return matchError(e.Unwrap(), match)
+	case interface{ Unwrap() []error }:
+		return slices.ContainsFunc(e.Unwrap(), func(err error) bool {
+			return matchError(err, match)
+		})
+	}
+	return false
+}
+
+// rollbackRuleFromContext returns the rollback rule declared on the proxied method.
+// the error names of the annotation should be registered by SentinelErrors.
+func rollbackRuleFromContext(c context.Context, config txConfig) (rollbackRule, error) {
+	rule := rollbackRule{
+		rollbackOn: config.rollbackOn,
+	}
+
+	inv, _ := invocation.FromContext(c)
+	for _, argument := range []string{rollbackForArgument, noRollbackForArgument} {
+		value, ok := inv.Argument(transactionalAnnotation, argument)
+		if !ok || value == "" {
+			continue
+		}
+
+		for _, name := range strings.Split(value, argumentValueSeparator) {
+			err, ok := config.sentinelErrors[strings.TrimSpace(name)]
+			if !ok {
+				return rollbackRule{}, fmt.Errorf("%w %s on %s of %s", ErrUnregisteredSentinelError, name, argument, inv)
+			}
+
+			if argument == rollbackForArgument {
+				rule.rollbackFor = append(rule.rollbackFor, err)
+			} else {
+				rule.noRollbackFor = append(rule.noRollbackFor, err)
+			}
+		}
+	}
+	return rule, nil
+}
+
 // TxMiddleware is a function that returns a middleware that manages transactions.
 // The middleware creates a new transaction if the transaction is not set in the context.
 // If the transaction is set in the context, the middleware manages the transaction.
+// The behavior with the existing transaction is decided by the propagation of the method.
 //
 // the middleware is used as follows:
 //
@@ -61,58 +765,947 @@ type TransactionFactory func() (Transaction, error)
 //
 //		txMiddleware := proxy.TxMiddleware(txFatory)
 func TxMiddleware(creator TransactionFactory) func(func(c context.Context) error) func(context.Context) error {
+	return TxMiddlewareWithOptions(creator)
+}
+
+// TxMiddlewareWithOptions is a function that returns a middleware that manages transactions with the options.
+//
+//	txMiddleware := proxy.TxMiddlewareWithOptions(txFatory,
+//		proxy.RollbackOn(func(err error) bool {
+//			return !errors.Is(err, service.ErrNotFound)
+//		}),
+//		proxy.SentinelErrors(map[string]error{
+//			"ErrAlreadyExists": service.ErrAlreadyExists,
+//		}),
+//	)
+func TxMiddlewareWithOptions(creator TransactionFactory, opts ...TxOption) func(func(c context.Context) error) func(context.Context) error {
+	config := txConfig{
+		sentinelErrors: map[string]error{},
+		managers:       map[string]TransactionFactory{},
+		isRetryable:    IsRetryable,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if _, ok := config.managers[DefaultTransactionManager]; !ok && creator != nil {
+		config.managers[DefaultTransactionManager] = creator
+	}
+
 	return func(next func(c context.Context) error) func(context.Context) error {
 		return func(c context.Context) error {
-			if creator == nil {
-				return ErrNilTransactionFactory
+			rule, err := rollbackRuleFromContext(c, config)
+			if err != nil {
+				return err
 			}
 
-			tx, err := creator()
+			retry, err := retryPolicyFromContext(c, config)
 			if err != nil {
 				return err
 			}
 
-			err = tx.From(c)
-			if err == nil {
-				return subTransaction(c, next, tx)
+			if config.outbox != nil {
+				c = context.WithValue(c, outboxKey{}, config.outbox)
 			}
 
-			newTransaction(c, next, tx)
-			return nil
+			if config.router != nil {
+				c = withDataSourceRoute(c, config.router)
+			}
+
+			// the transaction of the first manager wraps the others
+			names := managerNamesFromContext(c)
+			run := next
+			for i := len(names) - 1; i >= 0; i-- {
+				factory, ok := config.managers[names[i]]
+				if !ok {
+					if names[i] == DefaultTransactionManager {
+						return ErrNilTransactionFactory
+					}
+					return fmt.Errorf("%w %s", ErrUnknownTransactionManager, names[i])
+				}
+
+				inner := run
+				call := txCall{manager: names[i], rule: rule, retry: retry, listener: config.listeners}
+				run = func(c context.Context) error {
+					return runTransaction(c, inner, factory, call)
+				}
+			}
+			return run(c)
 		}
 	}
 }
 
-// newTransaction is a function that creates a new transaction.
-// The function creates a new transaction and manages the transaction.
-func newTransaction(
-	c context.Context, next func(c context.Context) error, tx Transaction,
+// runTransaction runs next in the transaction of the manager by the propagation of the method.
+func runTransaction(
+	c context.Context, next func(c context.Context) error, creator TransactionFactory, call txCall,
 ) error {
-	err := tx.Begin()
+	tx, err := creator()
 	if err != nil {
 		return err
 	}
 
-	c = tx.Regist(c)
-	if err := next(c); err != nil {
-		tx.Rollback()
-		return errors.Join(ErrRollbackTransaction, err)
-	}
-
-	tx.Commit()
-	return nil
-}
-
-// subTransaction is a function that manages the transaction.
-func subTransaction(
-	c context.Context, next func(c context.Context) error, tx Transaction,
-) error {
-	if err := next(c); err != nil {
-		if err := tx.Rollback(); err != nil {
-			return err
+	exist := tx.From(c) == nil
+	switch p := propagationFromContext(c); p {
+	case PropagationRequired:
+		if exist {
+			return subTransaction(c, next, tx, call, false)
 		}
 
-		return errors.Join(ErrRollbackTransaction, err)
-	}
-	return nil
+		return retryTransaction(c, next, creator, tx, call)
+	case PropagationRequiresNew:
+		if exist {
+			// tx is bound to the existing transaction. create another one.
+			if tx, err = creator(); err != nil {
+				return err
+			}
+		}
+		return retryTransaction(c, next, creator, tx, call)
+	case PropagationNested:
+		if !exist {
+			return retryTransaction(c, next, creator, tx, call)
+		}
+
+		if _, ok := tx.(SavepointTransaction); !ok {
+			return ErrNestedTransactionNotSupported
+		}
+		return subTransaction(c, next, tx, call, true)
+	case PropagationSupports:
+		if exist {
+			return subTransaction(c, next, tx, call, false)
+		}
+		return next(c)
+	case PropagationMandatory:
+		if !exist {
+			return ErrNoExistingTransaction
+		}
+		return subTransaction(c, next, tx, call, false)
+	case PropagationNotSupported:
+		if exist {
+			return suspendTransaction(c, next, tx, call)
+		}
+		return next(c)
+	case PropagationNever:
+		if exist {
+			return ErrExistingTransaction
+		}
+		return next(c)
+	default:
+		return fmt.Errorf("%w %s", ErrUnknownPropagation, p)
+	}
+}
+
+// retryTransaction runs next in the new transaction and re-runs it with a fresh transaction
+// while it fails with the retryable error. only the outermost transaction of the manager is retried,
+// because the work of the enclosing transaction can not be re-run by the inner method.
+func retryTransaction(
+	c context.Context, next func(c context.Context) error, creator TransactionFactory, tx Transaction, call txCall,
+) error {
+	if _, enclosed := txScopeFromContext(c, call.manager); call.retry.max <= 0 || enclosed {
+		return newTransaction(c, next, tx, call)
+	}
+
+	backoff := call.retry.backoff
+	for attempt := 0; ; attempt++ {
+		err := newTransaction(c, next, tx, call)
+		if err == nil || attempt >= call.retry.max || !call.retry.isRetryable(err) {
+			return err
+		}
+
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-c.Done():
+				timer.Stop()
+				return errors.Join(c.Err(), err)
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+
+		if tx, err = creator(); err != nil {
+			return err
+		}
+	}
+}
+
+// newTransaction is a function that creates a new transaction.
+// The function creates a new transaction and manages the transaction.
+// The transaction is bound to the deadline context if the timeout is declared,
+// and rolled back if the deadline expires.
+func newTransaction(
+	c context.Context, next func(c context.Context) error, tx Transaction, call txCall,
+) error {
+	opts, err := txOptionsFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if !opts.ReadOnly {
+		StickToPrimary(c)
+	}
+
+	// the callbacks after the completion run out of the transaction and its deadline
+	base := c
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		c, cancel = context.WithTimeout(c, opts.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	if err := tx.Begin(c, opts); err != nil {
+		err = &TxError{Op: TxOpBegin, Cause: err}
+		call.listener.OnBegin(base, call.event(base, 1, start, err))
+		return err
+	}
+	call.listener.OnBegin(base, call.event(base, 1, start, nil))
+
+	scope := &txScope{state: &txState{}, syncs: &txSynchronizations{}, isNew: true, depth: 1}
+	c = invocation.WithTransaction(tx.Regist(c), true)
+	committed, err := completeTransaction(withTxScope(c, call.manager, scope), next, tx, call, scope)
+
+	// only the error of the transaction operation is returned as TxError at the top level.
+	// the TxError wrapped in the error of the method is of the other transaction.
+	event := call.event(base, 1, start, nil)
+	txErr, _ := err.(*TxError)
+	switch {
+	case committed:
+		call.listener.OnCommit(base, event)
+	case txErr != nil && txErr.Op == TxOpCommit:
+		event.Err = txErr
+		call.listener.OnCommit(base, event)
+	case txErr != nil && txErr.Op == TxOpRollback:
+		event.Err, event.Cause = txErr, txErr.Err
+		call.listener.OnRollback(base, event)
+	default:
+		event.Cause = err
+		call.listener.OnRollback(base, event)
+	}
+
+	scope.syncs.afterCompletion(base, committed)
+	return err
+}
+
+// completeTransaction runs next and commits or rolls back the transaction.
+// it reports whether the transaction is committed.
+func completeTransaction(
+	c context.Context, next func(c context.Context) error, tx Transaction, call txCall, scope *txScope,
+) (bool, error) {
+	rollbackFn := rollbackOf(c, tx)
+	err := next(c)
+	if err != nil && call.rule.shouldRollback(err) {
+		return false, rollback(rollbackFn, err)
+	}
+
+	if ctxErr := c.Err(); ctxErr != nil {
+		return false, rollback(rollbackFn, errors.Join(ctxErr, err))
+	}
+
+	if scope.state.rollbackOnly.Load() {
+		return false, rollbackOnly(rollbackFn, scope, err)
+	}
+
+	if syncErr := scope.syncs.beforeCommit(c); syncErr != nil {
+		return false, rollback(rollbackFn, errors.Join(syncErr, err))
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return false, &TxError{Op: TxOpCommit, Cause: commitErr, Err: err}
+	}
+	return true, err
+}
+
+// rollbackOf returns the rollback of the transaction bound to c.
+// database/sql rolls back the transaction by itself when c is done,
+// so the rollback of the done transaction succeeds after c is done.
+func rollbackOf(c context.Context, tx Transaction) func() error {
+	return func() error {
+		err := tx.Rollback()
+		if err != nil && c.Err() != nil && errors.Is(err, sql.ErrTxDone) {
+			return nil
+		}
+		return err
+	}
+}
+
+// subTransaction is a function that manages the transaction.
+// if the transaction supports the savepoint, next runs in the savepoint and
+// only the work of next is rolled back when next fails.
+// otherwise the transaction is marked as rollback-only and rolled back by
+// the method that began it.
+func subTransaction(
+	c context.Context, next func(c context.Context) error, tx Transaction, call txCall, nested bool,
+) (err error) {
+	parent, hasParent := txScopeFromContext(c, call.manager)
+	depth := 1
+	if hasParent {
+		depth = parent.depth + 1
+	}
+
+	defer func(start time.Time) {
+		call.listener.OnJoin(c, call.event(c, depth, start, err))
+	}(time.Now())
+
+	c = invocation.WithTransaction(c, true)
+
+	if sp, ok := tx.(SavepointTransaction); ok {
+		return savepointTransaction(c, next, sp, call, parent, nested)
+	}
+
+	scope := &txScope{state: &txState{}, depth: 1}
+	if hasParent {
+		scope.state = parent.state
+		scope.syncs = parent.syncs
+		scope.depth = parent.depth + 1
+	}
+
+	err = next(withTxScope(c, call.manager, scope))
+	if err == nil || !call.rule.shouldRollback(err) {
+		return err
+	}
+
+	// the transaction began out of the middleware is rolled back here
+	if !hasParent {
+		return rollback(tx.Rollback, err)
+	}
+
+	scope.state.rollbackOnly.Store(true)
+	return errors.Join(ErrRollbackTransaction, err)
+}
+
+// savepointTransaction runs next in the savepoint of the existing transaction.
+// the name of the savepoint is unique on the nested depth.
+// failures of creating, rolling back to and releasing the savepoint are
+// reported as begin, rollback and commit of TxError.
+// the joined call shares the rollback-only mark with the transaction,
+// and only the nested call rolls back the savepoint by its own mark.
+func savepointTransaction(
+	c context.Context, next func(c context.Context) error, sp SavepointTransaction, call txCall,
+	parent *txScope, nested bool,
+) error {
+	joined := parent != nil && !nested
+	scope := &txScope{state: &txState{}, depth: 1}
+	if parent != nil {
+		scope.depth = parent.depth + 1
+		if joined {
+			scope.state = parent.state
+		}
+		if parent.syncs != nil {
+			scope.syncs = &txSynchronizations{}
+		}
+	}
+
+	name := savepointNamePrefix + strconv.Itoa(scope.depth)
+	if err := sp.Savepoint(name); err != nil {
+		return &TxError{Op: TxOpBegin, Cause: err}
+	}
+
+	// the synchronizations of the savepoint are discarded with its work
+	rollbackTo := func() error {
+		if err := sp.RollbackTo(name); err != nil {
+			return err
+		}
+
+		scope.syncs.afterCompletion(c, false)
+		return nil
+	}
+
+	err := next(withTxScope(c, call.manager, scope))
+	if err != nil && call.rule.shouldRollback(err) {
+		return rollback(rollbackTo, err)
+	}
+
+	if !joined && scope.state.rollbackOnly.Load() {
+		return rollbackOnly(rollbackTo, scope, err)
+	}
+
+	releaseErr := sp.Release(name)
+	if parent != nil {
+		parent.syncs.join(scope.syncs)
+	}
+
+	if releaseErr != nil {
+		return &TxError{Op: TxOpCommit, Cause: releaseErr, Err: err}
+	}
+	return err
+}
+
+// rollbackOnly rolls back the transaction marked as rollback-only.
+// if the nested method marked it, ErrUnexpectedRollback is returned.
+func rollbackOnly(rollbackFn func() error, scope *txScope, err error) error {
+	if !scope.rollbackOnly.Load() {
+		err = errors.Join(ErrUnexpectedRollback, err)
+	}
+
+	if rollbackErr := rollbackFn(); rollbackErr != nil {
+		return &TxError{Op: TxOpRollback, Cause: rollbackErr, Err: err}
+	}
+	return err
+}
+
+// rollback rolls back the transaction because of err.
+// the failure of the rollback is reported with err as TxError.
+func rollback(rollbackFn func() error, err error) error {
+	if rollbackErr := rollbackFn(); rollbackErr != nil {
+		return &TxError{Op: TxOpRollback, Cause: rollbackErr, Err: err}
+	}
+	return errors.Join(ErrRollbackTransaction, err)
+}
+
+// suspendTransaction runs next without the existing transaction.
+func suspendTransaction(
+	c context.Context, next func(c context.Context) error, tx Transaction, call txCall,
+) error {
+	suspender, ok := tx.(TransactionSuspender)
+	if !ok {
+		return ErrSuspendNotSupported
+	}
+	c = invocation.WithTransaction(suspender.Suspend(c), false)
+	return next(withTxScope(c, call.manager, &txScope{}))
+}
+
+// OutboxEvent is the event stored in the outbox with the transaction
+// and published by Relay after the transaction commits.
+type OutboxEvent struct {
+	// ID is assigned by the outbox store.
+	ID        int64
+	Topic     string
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// OutboxStore stores the events of the outbox.
+type OutboxStore interface {
+	// Save stores the event with the transaction of the context.
+	Save(c context.Context, event OutboxEvent) error
+
+	// Fetch returns the unpublished events in stored order up to limit.
+	Fetch(c context.Context, limit int) ([]OutboxEvent, error)
+
+	// MarkPublished marks the events as published.
+	MarkPublished(c context.Context, ids ...int64) error
+}
+
+// outboxKey is the context key of the outbox store.
+type outboxKey struct{}
+
+// OutboxWriter adds the events to the outbox in the transaction of the context.
+type OutboxWriter struct {
+	c     context.Context
+	store OutboxStore
+}
+
+// Outbox returns the outbox writer of the transactional method.
+// the event is stored through the transaction of the method, so it is published
+// only if the transaction commits.
+//
+//	func (s *orderService) Create(c context.Context, order dto.Order) error {
+//		...
+//		return proxy.Outbox(c).Add(proxy.OutboxEvent{Topic: "order.created", Payload: payload})
+//	}
+func Outbox(c context.Context) *OutboxWriter {
+	store, _ := c.Value(outboxKey{}).(OutboxStore)
+	return &OutboxWriter{
+		c:     c,
+		store: store,
+	}
+}
+
+// Add stores the event to the outbox.
+// ErrNoTransaction is returned out of the transactional method.
+func (w *OutboxWriter) Add(event OutboxEvent) error {
+	if w.store == nil {
+		return ErrNoOutbox
+	}
+
+	if !TxStatusFromContext(w.c).IsActive {
+		return ErrNoTransaction
+	}
+
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	return w.store.Save(w.c, event)
+}
+
+// Publisher publishes the events of the outbox.
+type Publisher interface {
+	Publish(c context.Context, event OutboxEvent) error
+}
+
+// PublisherFunc is an adapter to use the function as Publisher.
+type PublisherFunc func(c context.Context, event OutboxEvent) error
+
+func (f PublisherFunc) Publish(c context.Context, event OutboxEvent) error {
+	return f(c, event)
+}
+
+const (
+	defaultRelayInterval  = time.Second
+	defaultRelayBatchSize = 100
+)
+
+// RelayOption configures the relay.
+type RelayOption func(*Relay)
+
+// RelayInterval sets the polling interval of the relay. default is 1s.
+func RelayInterval(interval time.Duration) RelayOption {
+	return func(r *Relay) {
+		r.interval = interval
+	}
+}
+
+// RelayBatchSize sets the max number of events dispatched by a poll. default is 100.
+func RelayBatchSize(size int) RelayOption {
+	return func(r *Relay) {
+		r.batchSize = size
+	}
+}
+
+// RelayErrorHandler sets the handler of the errors while Run.
+func RelayErrorHandler(f func(err error)) RelayOption {
+	return func(r *Relay) {
+		r.onError = f
+	}
+}
+
+// Relay polls the committed events of the outbox and dispatches them to the publisher.
+// the events are published at least once, in stored order.
+type Relay struct {
+	store     OutboxStore
+	publisher Publisher
+	interval  time.Duration
+	batchSize int
+	onError   func(err error)
+}
+
+// NewRelay returns a relay of the outbox store.
+//
+//	relay := proxy.NewRelay(store, publisher, proxy.RelayInterval(500*time.Millisecond))
+//	go relay.Run(c)
+func NewRelay(store OutboxStore, publisher Publisher, opts ...RelayOption) *Relay {
+	r := &Relay{
+		store:     store,
+		publisher: publisher,
+		interval:  defaultRelayInterval,
+		batchSize: defaultRelayBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run dispatches the events on every interval until the context is done.
+func (r *Relay) Run(c context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Dispatch(c); err != nil && r.onError != nil {
+			r.onError(err)
+		}
+
+		select {
+		case <-c.Done():
+			return c.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch publishes a batch of the unpublished events and returns the number of published events.
+// it stops at the first failed event to keep the order, and the event is retried by the next dispatch.
+func (r *Relay) Dispatch(c context.Context) (int, error) {
+	events, err := r.store.Fetch(c, r.batchSize)
+	if err != nil {
+		return 0, errors.Join(errors.New("failed to fetch outbox events"), err)
+	}
+
+	published := []int64{}
+	var publishErr error
+	for _, event := range events {
+		if err := r.publisher.Publish(c, event); err != nil {
+			publishErr = errors.Join(fmt.Errorf("failed to publish outbox event %d", event.ID), err)
+			break
+		}
+		published = append(published, event.ID)
+	}
+
+	if len(published) > 0 {
+		if err := r.store.MarkPublished(c, published...); err != nil {
+			return 0, errors.Join(errors.New("failed to mark outbox events as published"), err, publishErr)
+		}
+	}
+	return len(published), publishErr
+}
+
+// MemoryOutboxStore implements OutboxStore in memory.
+// the event is stored when the transaction of the context commits and discarded when it rolls back.
+// the events are lost when the process exits, so it is for the tests and the single process.
+type MemoryOutboxStore struct {
+	mu     sync.Mutex
+	seq    int64
+	events []OutboxEvent
+}
+
+// NewMemoryOutboxStore returns an empty outbox store in memory.
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{}
+}
+
+func (s *MemoryOutboxStore) Save(c context.Context, event OutboxEvent) error {
+	return RegisterSynchronization(c, Synchronization{
+		AfterCommit: func(context.Context) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			s.seq++
+			event.ID = s.seq
+			s.events = append(s.events, event)
+		},
+	})
+}
+
+func (s *MemoryOutboxStore) Fetch(c context.Context, limit int) ([]OutboxEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.events[:min(limit, len(s.events))]), nil
+}
+
+func (s *MemoryOutboxStore) MarkPublished(c context.Context, ids ...int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = slices.DeleteFunc(s.events, func(event OutboxEvent) bool {
+		return slices.Contains(ids, event.ID)
+	})
+	return nil
+}
+
+// MemoryPublisher records the published events in memory.
+// the zero value is ready to use.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []OutboxEvent
+}
+
+func (p *MemoryPublisher) Publish(c context.Context, event OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events returns the published events in published order.
+func (p *MemoryPublisher) Events() []OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.events)
+}
+
+// DataSourceRouter resolves the replica of the primary data source for the read-only call.
+type DataSourceRouter interface {
+	// Replica returns the replica of the primary. ok is false if the primary has no replica.
+	Replica(c context.Context, primary any) (any, bool)
+}
+
+// ReplicaRouter is the DataSourceRouter that selects the replicas of the primary in round robin.
+type ReplicaRouter struct {
+	mu       sync.RWMutex
+	replicas map[any][]any
+	next     atomic.Uint64
+}
+
+// NewReplicaRouter returns an empty replica router.
+//
+//	router := proxy.NewReplicaRouter().Add(primary, replica1, replica2)
+func NewReplicaRouter() *ReplicaRouter {
+	return &ReplicaRouter{
+		replicas: map[any][]any{},
+	}
+}
+
+// Add adds the replicas of the primary.
+// the primary and the replicas should be the same type. e.g. *sql.DB
+func (r *ReplicaRouter) Add(primary any, replicas ...any) *ReplicaRouter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replicas[primary] = append(r.replicas[primary], replicas...)
+	return r
+}
+
+func (r *ReplicaRouter) Replica(_ context.Context, primary any) (any, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	replicas := r.replicas[primary]
+	if len(replicas) == 0 {
+		return nil, false
+	}
+	return replicas[(r.next.Add(1)-1)%uint64(len(replicas))], true
+}
+
+// dataSourceRoute is the routing of the data source shared by the calls of the request.
+type dataSourceRoute struct {
+	router  DataSourceRouter
+	written atomic.Bool
+}
+
+type dataSourceRouteKey struct{}
+
+// readOnlyRouteKey marks the context of the read-only method.
+type readOnlyRouteKey struct{}
+
+// WithDataSourceRoute returns a context that routes the read-only calls by the router.
+// the calls that share the context stick to the primary after the write, so the reads see their own writes.
+// install it at the request boundary. otherwise the route is created by the outermost proxied method
+// and discarded when it returns, so the following calls of the request read the replica again.
+//
+//	func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+//		c := proxy.WithDataSourceRoute(r.Context(), router)
+//		...
+//	}
+func WithDataSourceRoute(c context.Context, router DataSourceRouter) context.Context {
+	return context.WithValue(c, dataSourceRouteKey{}, &dataSourceRoute{router: router})
+}
+
+func withDataSourceRoute(c context.Context, router DataSourceRouter) context.Context {
+	if _, ok := c.Value(dataSourceRouteKey{}).(*dataSourceRoute); ok {
+		return c
+	}
+	return WithDataSourceRoute(c, router)
+}
+
+// StickToPrimary routes the following calls that share the route of the context to the primary.
+// the transaction that is not read-only calls it. the write out of the transaction is not detected,
+// so call it after the write.
+func StickToPrimary(c context.Context) {
+	if route, ok := c.Value(dataSourceRouteKey{}).(*dataSourceRoute); ok {
+		route.written.Store(true)
+	}
+}
+
+// ReadOnlyMiddleware returns a middleware of @readonly.
+// the FromContext helpers of the adapters return the replica of the db in the method
+// until the write of the request.
+//
+//	"readonly": {proxy.ReadOnlyMiddleware(router)},
+func ReadOnlyMiddleware(router DataSourceRouter) func(func(c context.Context) error) func(context.Context) error {
+	return func(next func(c context.Context) error) func(context.Context) error {
+		return func(c context.Context) error {
+			c = withDataSourceRoute(c, router)
+			return next(context.WithValue(c, readOnlyRouteKey{}, true))
+		}
+	}
+}
+
+func isReadOnlyRoute(c context.Context) bool {
+	readOnly, _ := c.Value(readOnlyRouteKey{}).(bool)
+	return readOnly
+}
+
+// replicaOf returns the replica of the primary if the read-only call is routed.
+// the primary is returned after the write of the request.
+func replicaOf[T any](c context.Context, primary T, readOnly bool) T {
+	if !readOnly {
+		return primary
+	}
+
+	route, ok := c.Value(dataSourceRouteKey{}).(*dataSourceRoute)
+	if !ok || route.router == nil || route.written.Load() {
+		return primary
+	}
+
+	replica, ok := route.router.Replica(c, primary)
+	if !ok {
+		return primary
+	}
+
+	if db, ok := replica.(T); ok {
+		return db
+	}
+	return primary
+}
+
+// TxEvent is the lifecycle event of the transaction.
+type TxEvent struct {
+	// Manager is the name of the transaction manager.
+	Manager string
+
+	// Method is the qualified name of the transactional method. e.g. Foo.Create
+	Method string
+
+	// Depth is the nested depth of the method in the transaction. the method that began it is 1.
+	Depth int
+
+	// Duration is the time taken by Begin on OnBegin, the time the transaction stays open on
+	// OnCommit and OnRollback, and the time of the joined method on OnJoin.
+	Duration time.Duration
+
+	// Err is the error of the event. it is the failure of Begin, Commit and Rollback on
+	// OnBegin, OnCommit and OnRollback, and the error of the joined method on OnJoin.
+	Err error
+
+	// Cause is the error that caused the rollback on OnRollback.
+	Cause error
+}
+
+// TxListener listens the lifecycle events of the transaction.
+// the listener is called synchronously, so it should not block.
+type TxListener interface {
+	// OnBegin is called after the method begins the transaction.
+	OnBegin(c context.Context, event TxEvent)
+
+	// OnCommit is called after the transaction is committed.
+	OnCommit(c context.Context, event TxEvent)
+
+	// OnRollback is called after the transaction is rolled back.
+	OnRollback(c context.Context, event TxEvent)
+
+	// OnJoin is called after the method joined to the existing transaction returns.
+	OnJoin(c context.Context, event TxEvent)
+}
+
+type txListeners []TxListener
+
+func (l txListeners) OnBegin(c context.Context, event TxEvent) {
+	for _, listener := range l {
+		listener.OnBegin(c, event)
+	}
+}
+
+func (l txListeners) OnCommit(c context.Context, event TxEvent) {
+	for _, listener := range l {
+		listener.OnCommit(c, event)
+	}
+}
+
+func (l txListeners) OnRollback(c context.Context, event TxEvent) {
+	for _, listener := range l {
+		listener.OnRollback(c, event)
+	}
+}
+
+func (l txListeners) OnJoin(c context.Context, event TxEvent) {
+	for _, listener := range l {
+		listener.OnJoin(c, event)
+	}
+}
+
+// SlogTxListener logs the lifecycle events of the transaction with slog.
+// the event is logged at debug level, or warn level if it has the error.
+type SlogTxListener struct {
+	logger *slog.Logger
+}
+
+// NewSlogTxListener returns a listener that logs with the logger. if logger is nil, slog.Default is used.
+func NewSlogTxListener(logger *slog.Logger) *SlogTxListener {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &SlogTxListener{
+		logger: logger,
+	}
+}
+
+func (l *SlogTxListener) OnBegin(c context.Context, event TxEvent) {
+	l.log(c, "transaction began", event)
+}
+
+func (l *SlogTxListener) OnCommit(c context.Context, event TxEvent) {
+	l.log(c, "transaction committed", event)
+}
+
+func (l *SlogTxListener) OnRollback(c context.Context, event TxEvent) {
+	l.log(c, "transaction rolled back", event)
+}
+
+func (l *SlogTxListener) OnJoin(c context.Context, event TxEvent) {
+	l.log(c, "transaction joined", event)
+}
+
+func (l *SlogTxListener) log(c context.Context, msg string, event TxEvent) {
+	level := slog.LevelDebug
+	attrs := []slog.Attr{
+		slog.String("manager", event.Manager),
+		slog.String("method", event.Method),
+		slog.Int("depth", event.Depth),
+		slog.Duration("duration", event.Duration),
+	}
+
+	if event.Err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", event.Err.Error()))
+	}
+
+	if event.Cause != nil {
+		attrs = append(attrs, slog.String("cause", event.Cause.Error()))
+	}
+	l.logger.LogAttrs(c, level, msg, attrs...)
+}
+
+// ExpvarTxListener counts the lifecycle events of the transaction and publishes them with expvar.
+//
+//	begin, begin_error, commit, commit_error, rollback, rollback_error, join, join_error: the number of the events
+//	open_ns: the total time the transactions stay open
+//	depth_{n}: the number of the methods joined at the nested depth n
+type ExpvarTxListener struct {
+	vars *expvar.Map
+}
+
+// expvarMu serializes publishing the expvar maps of the listeners.
+var expvarMu sync.Mutex
+
+// NewExpvarTxListener returns a listener that publishes the counters as the expvar map of the name.
+// the published map of the name is shared. if the name is published as the other type of expvar,
+// the counters are not published and only returned by Vars.
+func NewExpvarTxListener(name string) *ExpvarTxListener {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	vars := new(expvar.Map)
+	switch published := expvar.Get(name).(type) {
+	case nil:
+		expvar.Publish(name, vars)
+	case *expvar.Map:
+		vars = published
+	}
+
+	return &ExpvarTxListener{
+		vars: vars,
+	}
+}
+
+// Vars returns the published counters.
+func (l *ExpvarTxListener) Vars() *expvar.Map {
+	return l.vars
+}
+
+func (l *ExpvarTxListener) OnBegin(_ context.Context, event TxEvent) {
+	l.count("begin", event)
+}
+
+func (l *ExpvarTxListener) OnCommit(_ context.Context, event TxEvent) {
+	l.count("commit", event)
+	l.vars.Add("open_ns", int64(event.Duration))
+}
+
+func (l *ExpvarTxListener) OnRollback(_ context.Context, event TxEvent) {
+	l.count("rollback", event)
+	l.vars.Add("open_ns", int64(event.Duration))
+}
+
+func (l *ExpvarTxListener) OnJoin(_ context.Context, event TxEvent) {
+	l.count("join", event)
+	l.vars.Add("depth_"+strconv.Itoa(event.Depth), 1)
+}
+
+func (l *ExpvarTxListener) count(key string, event TxEvent) {
+	if event.Err != nil {
+		key += "_error"
+	}
+	l.vars.Add(key, 1)
 }
@@ -0,0 +1,96 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ISSuh/gen-go-proxy/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration file of the generator.
+//
+//	strict: true
+//	annotations:
+//	  - name: retry
+//	    requireContext: true
+//	    requireError: true
+//	    arguments:
+//	      - name: max
+//	        type: int
+//	        default: "3"
+//	rules:
+//	  - match: "service.*.Create*"
+//	    params: "context.Context, ..."
+//	    annotate: [transactional, retry(max=5)]
+type Config struct {
+	// Strict rejects annotations that are not declared on Annotations.
+	// default is true if Annotations is not empty.
+	Strict      *bool                     `yaml:"strict"`
+	Annotations []parser.AnnotationSchema `yaml:"annotations"`
+
+	// Rules attach annotations to the methods that match the pointcut.
+	Rules parser.Rules `yaml:"rules"`
+}
+
+func Load(path string) (Config, error) {
+	c := Config{}
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, errors.Join(fmt.Errorf("failed to read config file(%s)", path), err)
+	}
+
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return Config{}, errors.Join(fmt.Errorf("failed to parse config file(%s)", path), err)
+	}
+
+	if err := c.Rules.Validate(); err != nil {
+		return Config{}, errors.Join(fmt.Errorf("invalid rules on config file(%s)", path), err)
+	}
+	return c, nil
+}
+
+// Registry returns the annotation registry that has built-in and declared annotation schemas.
+func (c Config) Registry() (*parser.Registry, error) {
+	r := parser.NewRegistry()
+	for _, s := range c.Annotations {
+		if err := r.Register(s); err != nil {
+			return nil, err
+		}
+	}
+
+	strict := len(c.Annotations) != 0
+	if c.Strict != nil {
+		strict = *c.Strict
+	}
+
+	r.SetStrict(strict)
+	return r, nil
+}
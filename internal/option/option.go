@@ -25,6 +25,7 @@ package option
 import (
 	"errors"
 
+	"github.com/ISSuh/gen-go-proxy/internal/parser"
 	"github.com/alexflint/go-arg"
 )
 
@@ -35,10 +36,15 @@ type InterfacePackage struct {
 
 type Arguments struct {
 	InterfacePackage
-	Target          string `arg:"required,-t,--target" help:"target directory path of the interface source code file. is required"`
-	Output          string `arg:"-o,--output" help:"output file path.default is the same as the target interface source code file"`
-	Package         string `arg:"-p,--package" help:"package name of the generated code. default is the same as the target interface source code file"`
-	UseTxMiddleware bool   `arg:"-x,--use-tx-middleware" help:"generate transaction middleware. default is false"`
+	Target           string   `arg:"-t,--target" help:"target directory path of the interface source code file. target or from is required"`
+	From             []string `arg:"-f,--from,separate" help:"qualified name of the interface in the imported package. e.g. net/http.RoundTripper. repeat to generate multiple interfaces. target or from is required"`
+	Output           string   `arg:"-o,--output" help:"output file path.default is the same as the target interface source code file"`
+	Package          string   `arg:"-p,--package" help:"package name of the generated code. default is the same as the target interface source code file"`
+	UseTxMiddleware  bool     `arg:"-x,--use-tx-middleware" help:"generate transaction middleware. default is false"`
+	AnnotationSyntax string   `arg:"-s,--annotation-syntax" default:"all" help:"annotation syntax to recognize. all(@name and //proxy:name), at(@name only), directive(//proxy:name only)"`
+	Config           string   `arg:"-c,--config" help:"config file path. declare annotation schemas to validate annotations"`
+	ExtractInterface bool     `arg:"-e,--extract-interface" help:"write out the interface extracted from the struct that has annotated methods. default is false"`
+	TxAdapter        string   `arg:"-a,--tx-adapter" help:"comma separated database packages to generate transaction adapters. sql, sqlx, gorm, pgx. requires use-tx-middleware"`
 }
 
 func NewArguments() Arguments {
@@ -48,8 +54,30 @@ func NewArguments() Arguments {
 }
 
 func (a *Arguments) Validate() error {
-	if a.Target == "" {
+	if a.Target == "" && len(a.From) == 0 {
 		return errors.New("target interface source code file is empty")
 	}
+
+	if a.Target != "" && len(a.From) != 0 {
+		return errors.New("target and from can not be used together")
+	}
+
+	if len(a.From) != 0 && a.Output == "" {
+		return errors.New("output is required to generate proxy from the imported package")
+	}
+
+	if a.TxAdapter != "" {
+		if !a.UseTxMiddleware {
+			return errors.New("tx-adapter requires use-tx-middleware")
+		}
+
+		if _, err := parser.SplitTxAdapters(a.TxAdapter); err != nil {
+			return err
+		}
+	}
+
+	if err := parser.AnnotationSyntax(a.AnnotationSyntax).Validate(); err != nil {
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,76 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"embed"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+const (
+	txAdapterTemplatePathFormat = "templates/proxy_tx_adapter_%s.go.tmpl"
+
+	// TxAdapterFileNameFormat is the file name of the generated transaction adapter.
+	TxAdapterFileNameFormat = "proxy_tx_adapter_%s.go"
+)
+
+//go:embed templates/proxy_tx_adapter_*.go.tmpl
+var txAdapterTemplate embed.FS
+
+// TxAdapters are the database packages that have the transaction adapter.
+var TxAdapters = []string{"sql", "sqlx", "gorm", "pgx"}
+
+// SplitTxAdapters splits the comma separated adapter names. e.g. sql,gorm
+func SplitTxAdapters(s string) ([]string, error) {
+	adapters := []string{}
+	for _, adapter := range strings.Split(s, ",") {
+		adapter = strings.ToLower(strings.TrimSpace(adapter))
+		if adapter == "" || slices.Contains(adapters, adapter) {
+			continue
+		}
+
+		if !slices.Contains(TxAdapters, adapter) {
+			return nil, fmt.Errorf("unknown transaction adapter %q. must be one of %s", adapter, strings.Join(TxAdapters, ", "))
+		}
+
+		adapters = append(adapters, adapter)
+	}
+	return adapters, nil
+}
+
+// GenerateTxAdapter generates the transaction adapter of the database package.
+func (g *Generator) GenerateTxAdapter(outFilePath, adapter string, tmpl Template) error {
+	t, err := template.ParseFS(txAdapterTemplate, fmt.Sprintf(txAdapterTemplatePathFormat, adapter))
+	if err != nil {
+		return err
+	}
+
+	if err := g.generateFile(outFilePath, tmpl, t); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,354 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	annotationToken  = '@'
+	minAnnotationLen = 2
+
+	directivePrefix       = "//proxy:"
+	argumentSeparator     = ","
+	argumentKeySeparator  = "="
+	argumentOpenToken     = "("
+	argumentCloseToken    = ")"
+	positionalArgumentKey = ""
+
+	// argumentValueSeparator separates multiple values of an argument.
+	// e.g. @transactional(noRollbackFor=ErrNotFound|ErrAlreadyExists)
+	argumentValueSeparator = "|"
+
+	// orderAnnotation overrides the middleware order of the method.
+	// it is not a middleware annotation.
+	// e.g. @order(transactional, retry)
+	orderAnnotation = "order"
+
+	annotationOrderSeparator = " -> "
+)
+
+// AnnotationSyntax selects which comment forms are recognized as annotations.
+type AnnotationSyntax string
+
+const (
+	// AnnotationSyntaxAll recognizes both @name comments and //proxy:name directives.
+	AnnotationSyntaxAll AnnotationSyntax = "all"
+
+	// AnnotationSyntaxAt recognizes only @name comments.
+	AnnotationSyntaxAt AnnotationSyntax = "at"
+
+	// AnnotationSyntaxDirective recognizes only //proxy:name directives.
+	AnnotationSyntaxDirective AnnotationSyntax = "directive"
+)
+
+func (s AnnotationSyntax) Validate() error {
+	switch s {
+	case "", AnnotationSyntaxAll, AnnotationSyntaxAt, AnnotationSyntaxDirective:
+		return nil
+	}
+	return fmt.Errorf("invalid annotation syntax %q. must be one of %s, %s, %s",
+		string(s), AnnotationSyntaxAll, AnnotationSyntaxAt, AnnotationSyntaxDirective)
+}
+
+func (s AnnotationSyntax) useAt() bool {
+	return s != AnnotationSyntaxDirective
+}
+
+func (s AnnotationSyntax) useDirective() bool {
+	return s != AnnotationSyntaxAt
+}
+
+type Argument struct {
+	Key   string
+	Value string
+}
+
+type Arguments []Argument
+
+func (a Arguments) Get(key string) (string, bool) {
+	for _, arg := range a {
+		if arg.Key == key {
+			return arg.Value, true
+		}
+	}
+	return "", false
+}
+
+// Positional returns the values of arguments declared without a key.
+// e.g. @timeout(800ms)
+func (a Arguments) Positional() []string {
+	values := []string{}
+	for _, arg := range a {
+		if arg.Key == positionalArgumentKey {
+			values = append(values, arg.Value)
+		}
+	}
+	return values
+}
+
+type Annotation struct {
+	ProxyTypeName  string
+	AnnotationName string
+	MethodName     string
+	Arguments      Arguments
+	pos            token.Pos
+}
+
+type Annotations []Annotation
+
+func (a Annotations) Exist(annotation string) bool {
+	for _, an := range a {
+		if an.AnnotationName == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+func (a Annotations) Get(annotation string) (Annotation, bool) {
+	for _, an := range a {
+		if an.AnnotationName == annotation {
+			return an, true
+		}
+	}
+	return Annotation{}, false
+}
+
+func (a Annotations) Names() []string {
+	names := []string{}
+	for _, an := range a {
+		names = append(names, an.AnnotationName)
+	}
+	return names
+}
+
+// Format formats the annotations in order. e.g. "@transactional -> @retry"
+func (a Annotations) Format() string {
+	names := []string{}
+	for _, an := range a {
+		names = append(names, string(annotationToken)+an.AnnotationName)
+	}
+	return strings.Join(names, annotationOrderSeparator)
+}
+
+// Reverse returns the annotations in reverse order.
+func (a Annotations) Reverse() Annotations {
+	reversed := slices.Clone(a)
+	slices.Reverse(reversed)
+	return reversed
+}
+
+// sort sorts the annotations by priority. higher priority runs first.
+// annotations that have same priority keep the declared order.
+// the order annotation overrides the priority.
+func (a Annotations) sort(priority func(name string) int, order []string) Annotations {
+	sorted := slices.Clone(a)
+	slices.SortStableFunc(sorted, func(x, y Annotation) int {
+		return priority(y.AnnotationName) - priority(x.AnnotationName)
+	})
+
+	if len(order) == 0 {
+		return sorted
+	}
+
+	ordered := Annotations{}
+	for _, name := range order {
+		if an, ok := sorted.Get(name); ok && !ordered.Exist(name) {
+			ordered = append(ordered, an)
+		}
+	}
+
+	for _, an := range sorted {
+		if !ordered.Exist(an.AnnotationName) {
+			ordered = append(ordered, an)
+		}
+	}
+	return ordered
+}
+
+// parseAnnotation parses annotations from the doc comment of a method
+// in declared order. the first declared annotation runs first.
+// two forms are supported and can be selected by syntax.
+//
+//	// @transactional
+//	// @transactional(readOnly=true)
+//	//proxy:transactional readOnly=true
+func parseAnnotation(doc *ast.CommentGroup, methodName, proxyTypeName string, syntax AnnotationSyntax) Annotations {
+	if doc == nil {
+		return nil
+	}
+
+	annotations := Annotations{}
+	lines := commentLines(doc)
+	for index := range lines {
+		name, args, ok := parseAnnotationLine(lines[index].text, syntax)
+		if !ok {
+			continue
+		}
+
+		if annotations.Exist(name) {
+			continue
+		}
+
+		a := Annotation{
+			AnnotationName: name,
+			MethodName:     methodName,
+			ProxyTypeName:  proxyTypeName,
+			Arguments:      args,
+			pos:            lines[index].pos,
+		}
+
+		annotations = append(annotations, a)
+	}
+	return annotations
+}
+
+type commentLine struct {
+	text string
+	pos  token.Pos
+}
+
+// commentLines returns the lines of the comment group in declaration order.
+// unlike ast.CommentGroup.Text, directive comments are kept as is.
+func commentLines(doc *ast.CommentGroup) []commentLine {
+	lines := []commentLine{}
+	for _, comment := range doc.List {
+		if strings.HasPrefix(comment.Text, directivePrefix) {
+			lines = append(lines, commentLine{text: comment.Text, pos: comment.Slash})
+			continue
+		}
+
+		text := comment.Text
+		switch {
+		case strings.HasPrefix(text, "//"):
+			text = text[2:]
+		case strings.HasPrefix(text, "/*"):
+			text = strings.TrimSuffix(text[2:], "*/")
+		}
+
+		for _, line := range strings.Split(text, "\n") {
+			lines = append(lines, commentLine{text: strings.TrimSpace(line), pos: comment.Slash})
+		}
+	}
+	return lines
+}
+
+func parseAnnotationLine(line string, syntax AnnotationSyntax) (string, Arguments, bool) {
+	if strings.HasPrefix(line, directivePrefix) {
+		if !syntax.useDirective() {
+			return "", nil, false
+		}
+		return parseDirective(line[len(directivePrefix):])
+	}
+
+	if !syntax.useAt() || !isValidAnnotation(line) {
+		return "", nil, false
+	}
+
+	name, rawArgs, _ := strings.Cut(line[1:], argumentOpenToken)
+	args, ok := parseArguments(strings.TrimSuffix(rawArgs, argumentCloseToken), argumentSeparator)
+	if !ok {
+		return "", nil, false
+	}
+	return strings.ToLower(name), args, true
+}
+
+// parseDirective parses the body of a directive comment.
+// e.g. "transactional readOnly=true" of "//proxy:transactional readOnly=true"
+func parseDirective(s string) (string, Arguments, bool) {
+	name, rawArgs, _ := strings.Cut(s, " ")
+	if !isValidAnnotationName(name) {
+		return "", nil, false
+	}
+
+	args, ok := parseArguments(rawArgs, " ")
+	if !ok {
+		return "", nil, false
+	}
+	return strings.ToLower(name), args, true
+}
+
+func parseArguments(s, separator string) (Arguments, bool) {
+	args := Arguments{}
+	for _, field := range strings.Split(s, separator) {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		key, value, hasKey := strings.Cut(field, argumentKeySeparator)
+		if !hasKey {
+			args = append(args, Argument{Key: positionalArgumentKey, Value: field})
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		if !isValidAnnotationName(key) {
+			return nil, false
+		}
+
+		args = append(args, Argument{Key: key, Value: strings.TrimSpace(value)})
+	}
+	return args, true
+}
+
+func isValidAnnotation(s string) bool {
+	if len(s) < minAnnotationLen {
+		return false
+	}
+
+	if s[0] != annotationToken {
+		return false
+	}
+
+	name, args, hasArgs := strings.Cut(s[1:], argumentOpenToken)
+	if hasArgs && !strings.HasSuffix(args, argumentCloseToken) {
+		return false
+	}
+
+	if !hasArgs && strings.Contains(s, " ") {
+		return false
+	}
+
+	return isValidAnnotationName(name)
+}
+
+func isValidAnnotationName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
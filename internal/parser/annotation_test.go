@@ -0,0 +1,193 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func parseDoc(t *testing.T, src string) *ast.CommentGroup {
+	t.Helper()
+
+	node, err := parser.ParseFile(token.NewFileSet(), "doc.go", "package doc\n\n"+src+"\nfunc F() {}\n", parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	return node.Decls[0].(*ast.FuncDecl).Doc
+}
+
+func TestParseAnnotation(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    string
+		syntax AnnotationSyntax
+		want   []string
+		args   map[string]Arguments
+	}{
+		{
+			name: "at",
+			doc:  "// @transactional\n// @Audit",
+			want: []string{"transactional", "audit"},
+		},
+		{
+			name: "arguments",
+			doc:  "// @transactional(readOnly=true, timeout = 3s)",
+			want: []string{"transactional"},
+			args: map[string]Arguments{
+				"transactional": {{Key: "readOnly", Value: "true"}, {Key: "timeout", Value: "3s"}},
+			},
+		},
+		{
+			name: "positional argument",
+			doc:  "// @timeout(800ms)",
+			want: []string{"timeout"},
+			args: map[string]Arguments{
+				"timeout": {{Key: positionalArgumentKey, Value: "800ms"}},
+			},
+		},
+		{
+			name: "directive",
+			doc:  "//proxy:transactional readOnly=true propagation=REQUIRES_NEW",
+			want: []string{"transactional"},
+			args: map[string]Arguments{
+				"transactional": {{Key: "readOnly", Value: "true"}, {Key: "propagation", Value: "REQUIRES_NEW"}},
+			},
+		},
+		{
+			name: "block comment",
+			doc:  "/*\n @transactional\n @retry(max=2)\n*/",
+			want: []string{"transactional", "retry"},
+		},
+		{
+			name: "duplicated annotation keeps first",
+			doc:  "// @retry(max=2)\n// @retry(max=5)",
+			want: []string{"retry"},
+			args: map[string]Arguments{
+				"retry": {{Key: "max", Value: "2"}},
+			},
+		},
+		{
+			name: "ignores prose and email",
+			doc:  "// Create creates the foo.\n// contact me@example.com\n// @ transactional\n// @transactional(readOnly=true",
+			want: []string{},
+		},
+		{
+			name: "invalid argument key",
+			doc:  "// @transactional(read-only=true)",
+			want: []string{},
+		},
+		{
+			name:   "at syntax ignores directive",
+			doc:    "// @transactional\n//proxy:audit",
+			syntax: AnnotationSyntaxAt,
+			want:   []string{"transactional"},
+		},
+		{
+			name:   "directive syntax ignores at",
+			doc:    "// @transactional\n//proxy:audit",
+			syntax: AnnotationSyntaxDirective,
+			want:   []string{"audit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := parseAnnotation(parseDoc(t, tt.doc), "Create", "FooProxy", tt.syntax)
+			if got := annotations.Names(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("names = %v, want %v", got, tt.want)
+			}
+
+			for _, a := range annotations {
+				if a.MethodName != "Create" || a.ProxyTypeName != "FooProxy" {
+					t.Errorf("annotation %s has method %q of %q", a.AnnotationName, a.MethodName, a.ProxyTypeName)
+				}
+
+				want, ok := tt.args[a.AnnotationName]
+				if !ok {
+					continue
+				}
+
+				if !reflect.DeepEqual(a.Arguments, want) {
+					t.Errorf("arguments of %s = %v, want %v", a.AnnotationName, a.Arguments, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseAnnotationNilDoc(t *testing.T) {
+	if annotations := parseAnnotation(nil, "Create", "FooProxy", AnnotationSyntaxAll); annotations != nil {
+		t.Fatalf("annotations = %v, want nil", annotations)
+	}
+}
+
+func TestAnnotationsSort(t *testing.T) {
+	priorities := map[string]int{"transactional": 10, "retry": 20}
+	priority := func(name string) int { return priorities[name] }
+
+	annotations := Annotations{
+		{AnnotationName: "audit"},
+		{AnnotationName: "transactional"},
+		{AnnotationName: "retry"},
+	}
+
+	tests := []struct {
+		name  string
+		order []string
+		want  string
+	}{
+		{
+			name: "priority",
+			want: "@retry -> @transactional -> @audit",
+		},
+		{
+			name:  "order overrides priority",
+			order: []string{"transactional", "retry"},
+			want:  "@transactional -> @retry -> @audit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := annotations.sort(priority, tt.order).Format(); got != tt.want {
+				t.Fatalf("sorted = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotationSyntaxValidate(t *testing.T) {
+	for _, s := range []AnnotationSyntax{"", AnnotationSyntaxAll, AnnotationSyntaxAt, AnnotationSyntaxDirective} {
+		if err := s.Validate(); err != nil {
+			t.Errorf("Validate(%q) = %v", s, err)
+		}
+	}
+
+	if err := AnnotationSyntax("hash").Validate(); err == nil {
+		t.Error("Validate(hash) must fail")
+	}
+}
@@ -0,0 +1,116 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+const (
+	// funcTypeMethodName is the method name of the proxy for the function type.
+	funcTypeMethodName = "Call"
+	wrapFuncPrefix     = "Wrap"
+)
+
+// parseFuncType parses the function types that have annotations.
+// annotations are declared on the doc comment of the type declaration.
+//
+//	// @transactional
+//	type Handler func(ctx context.Context, req Request) (Response, error)
+func parseFuncType(node *ast.File, isDiffrentPackage bool, option ParseOption) ([]Interface, error) {
+	funcs := []Interface{}
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok || typeSpec.TypeParams != nil {
+				continue
+			}
+
+			funcType, ok := typeSpec.Type.(*ast.FuncType)
+			if !ok {
+				continue
+			}
+
+			if isDiffrentPackage && !typeSpec.Name.IsExported() {
+				continue
+			}
+
+			doc := typeSpec.Doc
+			if doc == nil && !genDecl.Lparen.IsValid() {
+				doc = genDecl.Doc
+			}
+
+			name := typeSpec.Name.Name
+			f := Interface{
+				ProxyTypeName:     name + proxySuffix,
+				InterfaceName:     name,
+				InterfacePackage:  node.Name.Name,
+				IsDiffrentPackage: isDiffrentPackage,
+				IsFunc:            true,
+				WrapFuncName:      wrapFuncName(name),
+			}
+
+			m, err := parseFuncMethod(f, funcTypeMethodName, doc, funcType, typeSpec.Pos(), option)
+			if err != nil {
+				// the function type that is not annotated on its declaration is not a proxy target
+				if len(parseAnnotation(doc, funcTypeMethodName, f.ProxyTypeName, option.Syntax)) == 0 {
+					continue
+				}
+				return nil, err
+			}
+
+			// the function type is proxied only if it is annotated by its declaration or the rules
+			if !m.UseProxy {
+				continue
+			}
+
+			m.Callee = proxyReceiverVar + "." + proxyTargetField
+			f.Methods = Methods{m}
+			f.AllAnnotations = f.Methods.AllAnnotations()
+			funcs = append(funcs, f)
+		}
+	}
+
+	return funcs, nil
+}
+
+// wrapFuncName returns the name of the function that wraps the function type.
+// e.g. WrapHandler of Handler, wrapHandler of handler
+func wrapFuncName(name string) string {
+	wrap := wrapFuncPrefix
+	if !ast.IsExported(name) {
+		wrap = strings.ToLower(wrapFuncPrefix)
+	}
+
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return wrap + string(r)
+}
@@ -0,0 +1,97 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuncTypeProxyGolden(t *testing.T) {
+	assertGolden(t, "functype", ParseParam{})
+}
+
+func TestParseFuncType(t *testing.T) {
+	src := `package service
+
+import "context"
+
+// @audit
+type Handler func(ctx context.Context, name string) (int, error)
+
+type Callback func(ctx context.Context) error
+
+// not annotated, so the invalid signature is ignored
+type Invalid func(a, b context.Context) error
+
+// @audit
+type Generic[T any] func(T) T
+`
+
+	option := ParseOption{Rules: Rules{{Match: "service.Callback.Call", Annotate: []string{"retry"}}}}
+	interfaces := mustParseSource(t, src, option, NewRegistry())
+	if got := interfaces.Names(); !reflect.DeepEqual(got, []string{"Handler", "Callback"}) {
+		t.Fatalf("interfaces = %v, want Handler and Callback", got)
+	}
+
+	handler := interfaces[0]
+	if !handler.IsFunc || handler.WrapFuncName != "WrapHandler" || handler.TargetType() != "Handler" {
+		t.Fatalf("handler = %+v", handler)
+	}
+
+	call := handler.Methods[0]
+	if call.Name != funcTypeMethodName || call.Callee != "p.target" || !call.HasContext || !call.HasError {
+		t.Fatalf("call = %+v", call)
+	}
+
+	if got := interfaces[1].Methods[0].Annotations.Format(); got != "@retry" {
+		t.Fatalf("Callback annotations = %q, want @retry by the rule", got)
+	}
+}
+
+func TestParseFuncTypeInvalidAnnotated(t *testing.T) {
+	src := `package service
+
+import "context"
+
+// @audit
+type Invalid func(a, b context.Context) error
+`
+
+	if _, err := parseSource(t, src, ParseOption{}, nil); err == nil {
+		t.Fatal("ParseInterface() must fail for the annotated function type that has two contexts")
+	}
+}
+
+func TestWrapFuncName(t *testing.T) {
+	tests := map[string]string{
+		"Handler":  "WrapHandler",
+		"listener": "wrapListener",
+	}
+
+	for name, want := range tests {
+		if got := wrapFuncName(name); got != want {
+			t.Errorf("wrapFuncName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
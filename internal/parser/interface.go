@@ -38,6 +38,40 @@ type Interface struct {
 	Methods           Methods
 	AllAnnotations    Annotations
 	types             *ast.InterfaceType
+
+	// InterfacePackagePath is the import path of the package of the proxy target.
+	// it is the package name if the import path is not resolved.
+	InterfacePackagePath string
+
+	// IsStruct is true if the proxy target is the struct type.
+	// InterfaceName is the name of the struct.
+	IsStruct bool
+
+	// ExtractedInterfaceName is the name of the interface extracted from
+	// the method set of the struct. empty if the interface is not written out.
+	ExtractedInterfaceName string
+
+	// IsFunc is true if the proxy target is the function type.
+	// the function type has the only method named Call and
+	// is wrapped by the function named WrapFuncName.
+	IsFunc       bool
+	WrapFuncName string
+
+	// imports are used by the methods of the struct declared on the other files.
+	imports []Import
+}
+
+// TargetType returns the type of the proxy target.
+func (i Interface) TargetType() string {
+	target := i.InterfaceName
+	if i.IsDiffrentPackage {
+		target = i.InterfacePackage + "." + target
+	}
+
+	if i.IsStruct {
+		target = "*" + target
+	}
+	return target
 }
 
 type Interfaces []Interface
@@ -50,15 +84,29 @@ func (i Interfaces) Names() []string {
 	return names
 }
 
-func ParseInterface(node *ast.File, isDiffrentPackage bool) ([]Interface, error) {
+// ParseOption configures how interfaces and annotations of the methods are collected.
+type ParseOption struct {
+	Syntax AnnotationSyntax
+	Rules  Rules
+
+	// Files are the other files of the package to find methods of the struct.
+	Files []*ast.File
+
+	// ExtractInterface writes out the interface extracted from the struct.
+	ExtractInterface bool
+}
+
+// ParseInterface parses the interfaces, the structs that have annotated methods
+// and the annotated function types.
+func ParseInterface(node *ast.File, isDiffrentPackage bool, option ParseOption) ([]Interface, error) {
 	interfaces, err := parseInterfaceType(node, isDiffrentPackage)
 	if err != nil {
 		return nil, err
 	}
 
-	for i, iface := range interfaces {
+	for i := range interfaces {
 		interfaces[i].ProxyTypeName = interfaces[i].InterfaceName + proxySuffix
-		m, err := parseMethod(interfaces[i].ProxyTypeName, iface.types)
+		m, err := parseMethod(interfaces[i], option)
 		if err != nil {
 			return nil, err
 		}
@@ -67,6 +115,18 @@ func ParseInterface(node *ast.File, isDiffrentPackage bool) ([]Interface, error)
 		interfaces[i].AllAnnotations = interfaces[i].Methods.AllAnnotations()
 	}
 
+	structs, err := parseStructType(node, isDiffrentPackage, option)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs, err := parseFuncType(node, isDiffrentPackage, option)
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces = append(interfaces, structs...)
+	interfaces = append(interfaces, funcs...)
 	return interfaces, nil
 }
 
@@ -0,0 +1,126 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInterfaceProxyGolden(t *testing.T) {
+	assertGolden(t, "interface", ParseParam{})
+}
+
+func TestInterfaceProxyOtherPackageGolden(t *testing.T) {
+	assertGolden(t, "package", ParseParam{
+		ProxyPackageName:     "proxy",
+		InterfacePackageName: "service",
+		InterfacePackagePath: "example.com/app/service",
+	})
+}
+
+func TestParseInterfaceMethods(t *testing.T) {
+	src := `package service
+
+import "context"
+
+type Foo interface {
+	// @audit
+	Find(context.Context, int, string) (p int, f bool, err error)
+
+	Names(ctx context.Context, names ...string)
+}
+`
+
+	interfaces := mustParseSource(t, src, ParseOption{}, nil)
+	find, names := interfaces[0].Methods[0], interfaces[0].Methods[1]
+
+	// unnamed and reserved parameters are renamed
+	if find.Params != "_userCtx context.Context, p1 int, p2 string" || find.ResultVars != "r0, r1, err" {
+		t.Fatalf("Find params = %q, results = %q", find.Params, find.ResultVars)
+	}
+
+	if !find.UseProxy || names.UseProxy {
+		t.Fatalf("UseProxy of Find = %v, Names = %v", find.UseProxy, names.UseProxy)
+	}
+
+	if names.ParamNamesWithHelperContext != "_helperCtx, names..." {
+		t.Fatalf("Names params = %q", names.ParamNamesWithHelperContext)
+	}
+
+	if got := find.InvocationVar(); got != "fooProxyFindInvocation" {
+		t.Fatalf("InvocationVar() = %q", got)
+	}
+}
+
+func TestParseInterfaceRejectsInvalidMethod(t *testing.T) {
+	tests := map[string]string{
+		"two contexts": "Find(a, b context.Context) error",
+		"two errors":   "Find() (error, error)",
+	}
+
+	for name, method := range tests {
+		t.Run(name, func(t *testing.T) {
+			src := "package service\n\nimport \"context\"\n\ntype Foo interface {\n\t// @audit\n\t" + method + "\n}\n\nvar _ context.Context\n"
+			if _, err := parseSource(t, src, ParseOption{}, nil); err == nil {
+				t.Fatalf("ParseInterface() must fail for %s", method)
+			}
+		})
+	}
+}
+
+func TestApplyCompensation(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel string
+		undo   string
+		want   string
+	}{
+		{name: "valid", cancel: "Cancel(ctx context.Context, id int) error", undo: "Cancel"},
+		{name: "undeclared", cancel: "Cancel(ctx context.Context, id int) error", undo: "Revert", want: "compensation method Revert is not declared"},
+		{name: "itself", cancel: "Cancel(ctx context.Context, id int) error", undo: "Reserve", want: "method can not compensate itself"},
+		{name: "params", cancel: "Cancel(ctx context.Context, id string) error", undo: "Cancel", want: "must have the same parameters"},
+		{name: "results", cancel: "Cancel(ctx context.Context, id int) (int, error)", undo: "Cancel", want: "must return only error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "package service\n\nimport \"context\"\n\ntype Foo interface {\n\t// @compensable(undo=" + tt.undo + ")\n\tReserve(ctx context.Context, id int) error\n\n\t" + tt.cancel + "\n}\n"
+			interfaces, err := parseSource(t, src, ParseOption{}, NewRegistry())
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Apply() = %v", err)
+				}
+
+				if got := interfaces[0].Methods[0].Compensation; got != tt.undo {
+					t.Fatalf("Compensation = %q, want %q", got, tt.undo)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Apply() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -26,6 +26,8 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/token"
+	"slices"
 	"strings"
 	"unicode"
 )
@@ -33,32 +35,16 @@ import (
 const (
 	transactionComment = "@transactional"
 	proxyComment       = "@proxy"
-	annotationToken    = '@'
-	minAnnotationLen   = 2
 
-	errorType   = "error"
-	contextType = "context.Context"
+	errorType     = "error"
+	contextType   = "context.Context"
+	variadicToken = "..."
 
 	userContextParam   = "_userCtx"
 	helperContextParam = "_helperCtx"
-)
-
-type Annotation struct {
-	ProxyTypeName  string
-	AnnotationName string
-	MethodName     string
-}
-
-type Annotations []Annotation
 
-func (a Annotations) Exist(annotation string) bool {
-	for _, an := range a {
-		if an.AnnotationName == annotation {
-			return true
-		}
-	}
-	return false
-}
+	invocationVarSuffix = "Invocation"
+)
 
 type Param struct {
 	Type       string
@@ -92,9 +78,12 @@ func (p Params) Format() string {
 func (p Params) FormatVars(useHelperContext bool) string {
 	params := []string{}
 	for _, param := range p {
-		if useHelperContext && param.Type == contextType {
+		switch {
+		case useHelperContext && param.Type == contextType:
 			params = append(params, helperContextParam)
-		} else {
+		case strings.HasPrefix(param.Type, variadicToken):
+			params = append(params, param.Var+variadicToken)
+		default:
 			params = append(params, param.Var)
 		}
 	}
@@ -144,7 +133,9 @@ func (r Results) HasError() bool {
 type Method struct {
 	ProxyTypeName               string
 	Name                        string
+	Callee                      string
 	Annotations                 Annotations
+	AnnotationOrder             string
 	Params                      string
 	ParamNames                  string
 	ParamNamesWithHelperContext string
@@ -157,10 +148,49 @@ type Method struct {
 	UseProxy                    bool
 	HasError                    bool
 	HasContext                  bool
+
+	// Compensation is the method invoked to compensate the method when the saga fails.
+	// it is resolved from @compensable(undo=...).
+	Compensation string
+
+	params Params
+	order  *Annotation
+}
+
+// WrappingAnnotations returns the annotations in wrapping order.
+// the middleware of the last annotation wraps the target first.
+func (m Method) WrappingAnnotations() Annotations {
+	return m.Annotations.Reverse()
+}
+
+// sortAnnotations sorts the annotations by priority and the order annotation.
+func (m *Method) sortAnnotations(priority func(name string) int) {
+	order := []string{}
+	if m.order != nil {
+		order = m.order.Arguments.Positional()
+	}
+
+	m.Annotations = m.Annotations.sort(priority, order)
+	m.AnnotationOrder = m.Annotations.Format()
+}
+
+// InvocationVar returns the variable name of the invocation metadata of the method.
+// e.g. fooProxyCreateInvocation
+func (m Method) InvocationVar() string {
+	r := []rune(m.ProxyTypeName)
+	r[0] = unicode.ToLower(r[0])
+	return string(r) + m.Name + invocationVarSuffix
 }
 
 type Methods []Method
 
+// annotated reports whether any method has annotations.
+func (m Methods) annotated() bool {
+	return slices.ContainsFunc(m, func(method Method) bool {
+		return method.UseProxy
+	})
+}
+
 func (m Methods) AllAnnotations() Annotations {
 	annotations := Annotations{}
 	for _, method := range m {
@@ -175,9 +205,9 @@ func (m Methods) AllAnnotations() Annotations {
 	return annotations
 }
 
-func parseMethod(proxyTypeName string, iface *ast.InterfaceType) ([]Method, error) {
+func parseMethod(iface Interface, option ParseOption) ([]Method, error) {
 	methods := []Method{}
-	for _, method := range iface.Methods.List {
+	for _, method := range iface.types.Methods.List {
 		if len(method.Names) == 0 {
 			continue
 		}
@@ -188,37 +218,9 @@ func parseMethod(proxyTypeName string, iface *ast.InterfaceType) ([]Method, erro
 			return nil, fmt.Errorf("method %s is not a function", methodName)
 		}
 
-		annotations := parseAnnotation(method, methodName, proxyTypeName)
-
-		params, err := parseMethodParams(funcType)
+		m, err := parseFuncMethod(iface, methodName, method.Doc, funcType, method.Pos(), option)
 		if err != nil {
-			return nil, errors.Join(fmt.Errorf("failed to parse method params for %s", methodName), err)
-		}
-
-		results, err := parseMethodResults(funcType)
-		if err != nil {
-			return nil, errors.Join(fmt.Errorf("failed to parse method results for %s", methodName), err)
-		}
-
-		m := Method{
-			ProxyTypeName: proxyTypeName,
-			Name:          methodName,
-			Annotations:   annotations,
-			UseProxy:      len(annotations) != 0,
-			Params:        params.Format(),
-			ParamNames:    params.FormatVars(false),
-			Results:       results,
-			ResultVars:    results.FormatVars(),
-			ResultTypes:   results.FormatType(),
-			HasResults:    len(results) > 0,
-			HasError:      results.HasError(),
-			HasContext:    params.HasContext(),
-		}
-
-		if m.HasContext {
-			m.UserContextParam = userContextParam
-			m.HelperContextParam = helperContextParam
-			m.ParamNamesWithHelperContext = params.FormatVars(true)
+			return nil, err
 		}
 
 		methods = append(methods, m)
@@ -227,49 +229,91 @@ func parseMethod(proxyTypeName string, iface *ast.InterfaceType) ([]Method, erro
 	return methods, nil
 }
 
-func isTransactionMethod(method *ast.Field) bool {
-	return method.Doc != nil && strings.Contains(method.Doc.Text(), transactionComment)
-}
+// parseFuncMethod parses the method from the function type and the doc comment.
+// the method is declared on the interface or the struct.
+func parseFuncMethod(iface Interface, methodName string, doc *ast.CommentGroup, funcType *ast.FuncType, pos token.Pos, option ParseOption) (Method, error) {
+	proxyTypeName := iface.ProxyTypeName
+	params, err := parseMethodParams(funcType)
+	if err != nil {
+		return Method{}, errors.Join(fmt.Errorf("failed to parse method params for %s", methodName), err)
+	}
+
+	results, err := parseMethodResults(funcType)
+	if err != nil {
+		return Method{}, errors.Join(fmt.Errorf("failed to parse method results for %s", methodName), err)
+	}
+
+	annotations, order := splitOrderAnnotation(parseAnnotation(doc, methodName, proxyTypeName, option.Syntax))
+	annotations, err = option.Rules.merge(annotations, iface.InterfacePackage, iface.InterfaceName, methodName, proxyTypeName, params, pos)
+	if err != nil {
+		return Method{}, errors.Join(fmt.Errorf("failed to apply rules for %s", methodName), err)
+	}
 
-func isProxyMethod(method *ast.Field) bool {
-	return method.Doc != nil && strings.Contains(method.Doc.Text(), proxyComment)
+	return newMethod(proxyTypeName, methodName, params, results, annotations, order), nil
 }
 
-func parseAnnotation(method *ast.Field, methodName, proxyTypeName string) Annotations {
-	if method.Doc == nil {
-		return nil
+func newMethod(proxyTypeName, name string, params Params, results Results, annotations Annotations, order *Annotation) Method {
+	m := Method{
+		ProxyTypeName: proxyTypeName,
+		Name:          name,
+		Callee:        proxyReceiverVar + "." + proxyTargetField + "." + name,
+		Annotations:   annotations,
+		UseProxy:      len(annotations) != 0,
+		Params:        params.Format(),
+		ParamNames:    params.FormatVars(false),
+		Results:       results,
+		ResultVars:    results.FormatVars(),
+		ResultTypes:   results.FormatType(),
+		HasResults:    len(results) > 0,
+		HasError:      results.HasError(),
+		HasContext:    params.HasContext(),
+		params:        params,
+		order:         order,
 	}
+	m.sortAnnotations(func(string) int { return 0 })
 
-	annotations := Annotations{}
-	lines := strings.Split(method.Doc.Text(), "\n")
-	for i := range lines {
-		index := len(lines) - i - 1
-		if !isValidAnnotation(lines[index]) {
-			continue
-		}
+	if m.HasContext {
+		m.UserContextParam = userContextParam
+		m.HelperContextParam = helperContextParam
+		m.ParamNamesWithHelperContext = params.FormatVars(true)
+	}
+	return m
+}
 
-		annotation := strings.ToLower(lines[index][1:])
-		if annotations.Exist(annotation) {
+func splitOrderAnnotation(annotations Annotations) (Annotations, *Annotation) {
+	var order *Annotation
+	filtered := Annotations{}
+	for _, annotation := range annotations {
+		if annotation.AnnotationName == orderAnnotation {
+			for i := range annotation.Arguments {
+				annotation.Arguments[i].Value = strings.ToLower(annotation.Arguments[i].Value)
+			}
+			order = &annotation
 			continue
 		}
-
-		a := Annotation{
-			AnnotationName: strings.ToLower(lines[index][1:]),
-			MethodName:     methodName,
-			ProxyTypeName:  proxyTypeName,
-		}
-
-		annotations = append(annotations, a)
+		filtered = append(filtered, annotation)
 	}
-	return annotations
+	return filtered, order
 }
 
 func parseMethodParams(funcType *ast.FuncType) (Params, error) {
 	params := Params{}
 	hasContext := false
+	index := 0
 	for _, param := range funcType.Params.List {
-		for _, name := range param.Names {
+		names := param.Names
+		if len(names) == 0 {
+			// unnamed parameter. e.g. func(context.Context, Request)
+			names = []*ast.Ident{ast.NewIdent("")}
+		}
+
+		for _, name := range names {
 			paramName := name.Name
+			if paramName == "" || paramName == "_" || isReservedVarName(paramName) {
+				paramName = fmt.Sprintf("%s%d", unnamedParamPrefix, index)
+			}
+			index++
+
 			paramType := exprToString(param.Type)
 
 			if paramType == contextType {
@@ -381,25 +425,3 @@ func exprFuncToString(t *ast.FuncType) string {
 
 	return "func(" + strings.Join(params, ", ") + ") " + resultFormat
 }
-
-func isValidAnnotation(s string) bool {
-	if len(s) < minAnnotationLen {
-		return false
-	}
-
-	if s[0] != annotationToken {
-		return false
-	}
-
-	if strings.Contains(s, " ") {
-		return false
-	}
-
-	for _, r := range s[1:] {
-		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
-			return false
-		}
-	}
-
-	return true
-}
@@ -0,0 +1,304 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	importPathSeparator = "."
+	unnamedParamPrefix  = "p"
+
+	proxyReceiverVar = "p"
+	proxyTargetField = "target"
+	proxyFuncVar     = "f"
+	errorResultVar   = "err"
+	resultVarPrefix  = "r"
+)
+
+// FromParam is the parameter to generate proxy from the interface of the imported package.
+type FromParam struct {
+	// From is the qualified interface name. e.g. net/http.RoundTripper
+	From             string
+	OutFile          string
+	ProxyPackageName string
+	Registry         *Registry
+	Rules            Rules
+}
+
+// SplitFrom splits the qualified interface name to the import path and the interface name.
+// e.g. "github.com/acme/sdk.Client" to "github.com/acme/sdk", "Client"
+func SplitFrom(from string) (string, string, error) {
+	index := strings.LastIndex(from, importPathSeparator)
+	if index <= strings.LastIndex(from, "/") || index == len(from)-1 {
+		return "", "", fmt.Errorf("invalid interface %q. must be {import path}.{interface name}", from)
+	}
+	return from[:index], from[index+1:], nil
+}
+
+// ParseFrom loads the package by import path from the module and
+// parses the interface. annotations of the methods only come from the rules
+// because the source of the package can not be edited.
+func (g *Generator) ParseFrom(param FromParam) (Template, error) {
+	importPath, interfaceName, err := SplitFrom(param.From)
+	if err != nil {
+		return Template{}, err
+	}
+
+	pkg, fset, err := loadPackage(importPath)
+	if err != nil {
+		return Template{}, err
+	}
+
+	obj := pkg.Scope().Lookup(interfaceName)
+	if obj == nil {
+		return Template{}, fmt.Errorf("interface %s not found in package %s", interfaceName, importPath)
+	}
+
+	it, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return Template{}, fmt.Errorf("%s is not an interface", param.From)
+	}
+
+	imports := importCollector{}
+	iface := Interface{
+		ProxyTypeName:     interfaceName + proxySuffix,
+		InterfaceName:     interfaceName,
+		InterfacePackage:  imports.add(pkg),
+		IsDiffrentPackage: true,
+
+		InterfacePackagePath: pkg.Path(),
+	}
+
+	for i := 0; i < it.NumMethods(); i++ {
+		m, err := parseTypesMethod(iface, pkg.Name(), it.Method(i), imports.qualifier, param.Rules)
+		if err != nil {
+			return Template{}, err
+		}
+		iface.Methods = append(iface.Methods, m)
+	}
+	iface.AllAnnotations = iface.Methods.AllAnnotations()
+
+	interfaces := Interfaces{iface}
+	if param.Registry != nil {
+		if err := param.Registry.Apply(fset, interfaces); err != nil {
+			return Template{}, err
+		}
+	}
+
+	template := Template{
+		FileName: param.OutFile,
+		Data: &TemplateData{
+			SourceFile:  param.From,
+			PackageName: param.ProxyPackageName,
+			Imports:     imports.imports,
+			Interfaces:  interfaces,
+		},
+	}
+	return template, nil
+}
+
+// loadPackage type-checks the package from source.
+// the package is resolved by the go command, so the package of the standard library
+// and the module cache can be loaded in the module of current directory.
+func loadPackage(importPath string) (*types.Package, *token.FileSet, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset := token.NewFileSet()
+	imp, ok := importer.ForCompiler(fset, "source", nil).(types.ImporterFrom)
+	if !ok {
+		return nil, nil, errors.New("source importer is not supported")
+	}
+
+	pkg, err := imp.ImportFrom(importPath, dir, 0)
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("failed to load package %s", importPath), err)
+	}
+	return pkg, fset, nil
+}
+
+// parseTypesMethod parses the method of the interface declared in the package.
+// the rules match the method by the package of the interface, even if the method is embedded from another package.
+func parseTypesMethod(iface Interface, packageName string, fn *types.Func, qualifier types.Qualifier, rules Rules) (Method, error) {
+	if !fn.Exported() {
+		return Method{}, fmt.Errorf("interface %s has unexported method %s", iface.InterfaceName, fn.Name())
+	}
+
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok {
+		return Method{}, fmt.Errorf("method %s is not a function", fn.Name())
+	}
+
+	params, err := parseTypesParams(sig, qualifier)
+	if err != nil {
+		return Method{}, errors.Join(fmt.Errorf("failed to parse method params for %s", fn.Name()), err)
+	}
+
+	results, err := parseTypesResults(sig, qualifier)
+	if err != nil {
+		return Method{}, errors.Join(fmt.Errorf("failed to parse method results for %s", fn.Name()), err)
+	}
+
+	annotations, err := rules.merge(Annotations{}, packageName, iface.InterfaceName, fn.Name(), iface.ProxyTypeName, params, fn.Pos())
+	if err != nil {
+		return Method{}, errors.Join(fmt.Errorf("failed to apply rules for %s", fn.Name()), err)
+	}
+
+	annotations, order := splitOrderAnnotation(annotations)
+	return newMethod(iface.ProxyTypeName, fn.Name(), params, results, annotations, order), nil
+}
+
+func parseTypesParams(sig *types.Signature, qualifier types.Qualifier) (Params, error) {
+	params := Params{}
+	hasContext := false
+	for i := 0; i < sig.Params().Len(); i++ {
+		v := sig.Params().At(i)
+		paramName := v.Name()
+		if paramName == "" || paramName == "_" || isReservedVarName(paramName) {
+			paramName = fmt.Sprintf("%s%d", unnamedParamPrefix, i)
+		}
+
+		paramType := types.TypeString(v.Type(), qualifier)
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			paramType = "..." + types.TypeString(v.Type().(*types.Slice).Elem(), qualifier)
+		}
+
+		if paramType == contextType {
+			if hasContext {
+				return nil, errors.New("method must have at most one context.Context parameter")
+			}
+
+			hasContext = true
+			paramName = userContextParam
+		}
+
+		params = append(params, Param{
+			Type:       paramType,
+			Var:        paramName,
+			HasContext: hasContext,
+		})
+	}
+	return params, nil
+}
+
+// isReservedVarName reports whether the name is used by the generated proxy method.
+// the parameter that has the reserved name is renamed.
+func isReservedVarName(name string) bool {
+	switch name {
+	case proxyReceiverVar, proxyFuncVar, errorResultVar:
+		return true
+	}
+
+	_, err := strconv.Atoi(strings.TrimPrefix(name, resultVarPrefix))
+	return strings.HasPrefix(name, resultVarPrefix) && err == nil
+}
+
+func parseTypesResults(sig *types.Signature, qualifier types.Qualifier) (Results, error) {
+	results := Results{}
+	hasError := false
+	for i := 0; i < sig.Results().Len(); i++ {
+		resultType := types.TypeString(sig.Results().At(i).Type(), qualifier)
+		vars := fmt.Sprintf("%s%d", resultVarPrefix, i)
+		if resultType == errorType {
+			if hasError {
+				return nil, errors.New("proxy or transactional method must have at most one error result")
+			}
+
+			hasError = true
+			vars = errorResultVar
+		}
+
+		results = append(results, Result{
+			ResultType: resultType,
+			ResultVar:  vars,
+		})
+	}
+	return results, nil
+}
+
+// reservedImportNames are the names of the packages imported by the proxy template.
+var reservedImportNames = []string{"invocation", "saga"}
+
+// importCollector collects the imports used by the types of the methods.
+// the packages that have the same name are imported with numbered aliases. e.g. rand, rand2
+type importCollector struct {
+	imports []Import
+}
+
+// add returns the alias of the package and collects the import if it is not collected yet.
+func (c *importCollector) add(pkg *types.Package) string {
+	for _, i := range c.imports {
+		if i.Path == pkg.Path() {
+			return i.Alias
+		}
+	}
+
+	alias := pkg.Name()
+	for n := 2; c.used(alias); n++ {
+		alias = fmt.Sprintf("%s%d", pkg.Name(), n)
+	}
+
+	c.imports = append(c.imports, Import{Alias: alias, Path: pkg.Path()})
+	return alias
+}
+
+func (c *importCollector) used(alias string) bool {
+	if slices.Contains(reservedImportNames, alias) {
+		return true
+	}
+
+	return slices.ContainsFunc(c.imports, func(i Import) bool {
+		return i.Alias == alias
+	})
+}
+
+func (c *importCollector) qualifier(pkg *types.Package) string {
+	return c.add(pkg)
+}
+
+// ProxyFileName returns the file name of the proxy for the interface.
+// e.g. RoundTripper to round_tripper
+func ProxyFileName(interfaceName string) string {
+	var b strings.Builder
+	runes := []rune(interfaceName)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String() + proxyFileoutFilePathSuffix + sourceFIleExtention
+}
@@ -0,0 +1,149 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+func TestImportCollectorAlias(t *testing.T) {
+	c := importCollector{}
+	packages := []*types.Package{
+		types.NewPackage("crypto/rand", "rand"),
+		types.NewPackage("math/rand", "rand"),
+		types.NewPackage("example.com/v2/rand", "rand"),
+		types.NewPackage("crypto/rand", "rand"),
+		types.NewPackage("example.com/saga", "saga"),
+	}
+
+	aliases := []string{}
+	for _, pkg := range packages {
+		aliases = append(aliases, c.qualifier(pkg))
+	}
+
+	want := []string{"rand", "rand2", "rand3", "rand", "saga2"}
+	if !reflect.DeepEqual(aliases, want) {
+		t.Fatalf("aliases = %v, want %v", aliases, want)
+	}
+
+	if len(c.imports) != 4 {
+		t.Fatalf("imports = %v, want 4 imports", c.imports)
+	}
+}
+
+func TestSplitFrom(t *testing.T) {
+	tests := []struct {
+		from          string
+		importPath    string
+		interfaceName string
+		ok            bool
+	}{
+		{from: "net/http.RoundTripper", importPath: "net/http", interfaceName: "RoundTripper", ok: true},
+		{from: "github.com/acme/sdk.v2/client.Client", importPath: "github.com/acme/sdk.v2/client", interfaceName: "Client", ok: true},
+		{from: "io."},
+		{from: "Reader"},
+	}
+
+	for _, tt := range tests {
+		importPath, interfaceName, err := SplitFrom(tt.from)
+		if (err == nil) != tt.ok || importPath != tt.importPath || interfaceName != tt.interfaceName {
+			t.Errorf("SplitFrom(%q) = %q, %q, %v", tt.from, importPath, interfaceName, err)
+		}
+	}
+}
+
+func TestProxyFileName(t *testing.T) {
+	tests := map[string]string{
+		"RoundTripper":    "round_tripper_proxy.go",
+		"ReadWriteCloser": "read_write_closer_proxy.go",
+		"HTTPClient":      "http_client_proxy.go",
+		"Foo":             "foo_proxy.go",
+	}
+
+	for name, want := range tests {
+		if got := ProxyFileName(name); got != want {
+			t.Errorf("ProxyFileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseFrom(t *testing.T) {
+	g := NewGenerator()
+	tmpl, err := g.ParseFrom(FromParam{
+		From:             "io.ReadWriteCloser",
+		ProxyPackageName: "stdproxy",
+		Registry:         NewRegistry(),
+		Rules:            Rules{{Match: "io.ReadWriteCloser.Close", Annotate: []string{"audit"}}},
+	})
+	if err != nil {
+		t.Fatalf("ParseFrom() = %v", err)
+	}
+
+	iface := tmpl.Data.Interfaces[0]
+	if iface.TargetType() != "io.ReadWriteCloser" {
+		t.Fatalf("target type = %q", iface.TargetType())
+	}
+
+	names := []string{}
+	for _, m := range iface.Methods {
+		names = append(names, m.Name+":"+m.Annotations.Format())
+	}
+
+	want := []string{"Close:@audit", "Read:", "Write:"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("methods = %v, want %v", names, want)
+	}
+
+	if _, err := g.ParseFrom(FromParam{From: "io.Reader2"}); err == nil {
+		t.Fatal("ParseFrom() must fail for unknown interface")
+	}
+}
+
+func TestParseFromEmbeddedMethod(t *testing.T) {
+	// Close, Read and Seek of http.File are embedded from io
+	g := NewGenerator()
+	tmpl, err := g.ParseFrom(FromParam{
+		From:             "net/http.File",
+		ProxyPackageName: "stdproxy",
+		Registry:         NewRegistry(),
+		Rules: Rules{
+			{Match: "http.File.Close", Annotate: []string{"audit"}},
+			{Match: "io.*.Read", Annotate: []string{"trace"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ParseFrom() = %v", err)
+	}
+
+	names := []string{}
+	for _, m := range tmpl.Data.Interfaces[0].Methods {
+		names = append(names, m.Name+":"+m.Annotations.Format())
+	}
+
+	want := []string{"Close:@audit", "Read:", "Readdir:", "Seek:", "Stat:"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("methods = %v, want %v", names, want)
+	}
+}
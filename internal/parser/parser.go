@@ -33,16 +33,22 @@ import (
 	"go/parser"
 	"go/token"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"text/template"
 
+	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/imports"
 )
 
 const (
 	proxyFileoutFilePathSuffix = "_proxy"
 	sourceFIleExtention        = ".go"
+	testFileSuffix             = "_test.go"
 	proxyTemplatePath          = "templates/target_proxy.go.tmpl"
 	txTemplatePath             = "templates/proxy_middleware_tx.go.tmpl"
+	generatedCodeComment       = "// Code generated by gen-go-proxy. DO NOT EDIT."
 )
 
 //go:embed templates/target_proxy.go.tmpl
@@ -71,6 +77,10 @@ type ParseParam struct {
 	ProxyPackageName     string
 	InterfacePackageName string
 	InterfacePackagePath string
+	AnnotationSyntax     AnnotationSyntax
+	Registry             *Registry
+	Rules                Rules
+	ExtractInterface     bool
 }
 
 type Generator struct {
@@ -99,11 +109,41 @@ func (g *Generator) Parse(param ParseParam) (Template, error) {
 		isDiffrentPackage = true
 	}
 
-	iface, err := ParseInterface(node, isDiffrentPackage)
+	// skip the proxy generated before. e.g. the extracted interface of the struct
+	if isGeneratedProxy(node) {
+		return Template{Data: &TemplateData{PackageName: packageName}}, nil
+	}
+
+	files, err := g.parsePackageFiles(fset, param.TargetFileDir, param.TargetFile)
 	if err != nil {
 		return Template{}, err
 	}
 
+	option := ParseOption{
+		Syntax:           param.AnnotationSyntax,
+		Rules:            param.Rules,
+		Files:            files,
+		ExtractInterface: param.ExtractInterface,
+	}
+
+	iface, err := ParseInterface(node, isDiffrentPackage, option)
+	if err != nil {
+		return Template{}, err
+	}
+
+	// methods of the struct can be declared on the other files of the package
+	packagePath := resolvePackagePath(param, node.Name.Name)
+	for i := range iface {
+		imports = appendImports(imports, iface[i].imports)
+		iface[i].InterfacePackagePath = packagePath
+	}
+
+	if param.Registry != nil {
+		if err := param.Registry.Apply(fset, iface); err != nil {
+			return Template{}, err
+		}
+	}
+
 	template := Template{
 		FileName: param.OutFile,
 		FilePath: param.TargetFileDir,
@@ -133,6 +173,56 @@ func (g *Generator) paseImport(node *ast.File, interfacePackageName, interfacePa
 	return imports, nil
 }
 
+// parsePackageFiles parses the other go files of the target directory.
+// test files and the target file are excluded.
+func (g *Generator) parsePackageFiles(fset *token.FileSet, dir, targetFile string) ([]*ast.File, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []*ast.File{}
+	for _, item := range items {
+		name := item.Name()
+		if item.IsDir() || !strings.HasSuffix(name, sourceFIleExtention) || strings.HasSuffix(name, testFileSuffix) {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		if filepath.Clean(path) == filepath.Clean(targetFile) {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to parse file(%s)", path), err)
+		}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+func isGeneratedProxy(node *ast.File) bool {
+	for _, comment := range node.Comments {
+		if comment.Pos() > node.Package {
+			break
+		}
+
+		for _, c := range comment.List {
+			if c.Text == generatedCodeComment {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (g *Generator) GenerateProxy(outFilePath string, tmpl Template) error {
 	t, err := template.ParseFS(proxyTemplate, proxyTemplatePath)
 	if err != nil {
@@ -197,3 +287,33 @@ func (g *Generator) generateFile(outFilePath string, tmpl Template, t *template.
 
 	return nil
 }
+
+// resolvePackagePath returns the import path of the package of the target file.
+// it is resolved by the go.mod of the module that contains the target file,
+// and the package name is returned if the module is not found.
+func resolvePackagePath(param ParseParam, packageName string) string {
+	if param.InterfacePackagePath != "" {
+		return param.InterfacePackagePath
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(param.TargetFile))
+	if err != nil {
+		return packageName
+	}
+
+	for moduleDir := dir; ; moduleDir = filepath.Dir(moduleDir) {
+		data, err := os.ReadFile(filepath.Join(moduleDir, "go.mod"))
+		if err == nil {
+			modulePath := modfile.ModulePath(data)
+			rel, err := filepath.Rel(moduleDir, dir)
+			if modulePath == "" || err != nil {
+				return packageName
+			}
+			return path.Join(modulePath, filepath.ToSlash(rel))
+		}
+
+		if filepath.Dir(moduleDir) == moduleDir {
+			return packageName
+		}
+	}
+}
@@ -0,0 +1,167 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+const (
+	goldenSourceFile = "source.go"
+	goldenFile       = "source_proxy.go.golden"
+)
+
+// assertGolden generates the proxy of testdata/{name}/source.go and compares it with the golden file.
+// run go test with -update to rewrite the golden file.
+func assertGolden(t *testing.T, name string, param ParseParam) {
+	t.Helper()
+
+	dir := filepath.Join("testdata", name)
+	param.TargetFile = filepath.Join(dir, goldenSourceFile)
+	param.TargetFileDir = dir
+	if param.Registry == nil {
+		param.Registry = NewRegistry()
+	}
+
+	g := NewGenerator()
+	tmpl, err := g.Parse(param)
+	if err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), goldenFile)
+	if err := g.GenerateProxy(out, tmpl); err != nil {
+		t.Fatalf("GenerateProxy() = %v", err)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join(dir, goldenFile)
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file. run go test with -update: %v", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Fatalf("generated proxy of %s differs from %s\n%s", param.TargetFile, golden, got)
+	}
+}
+
+func parseFile(t *testing.T, src string) *ast.File {
+	t.Helper()
+
+	node, err := parser.ParseFile(token.NewFileSet(), "source.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	return node
+}
+
+// parseSource parses the interfaces of the source and applies the registry if it is not nil.
+func parseSource(t *testing.T, src string, option ParseOption, registry *Registry) (Interfaces, error) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, "source.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+
+	interfaces, err := ParseInterface(node, false, option)
+	if err != nil {
+		return nil, err
+	}
+
+	if registry != nil {
+		if err := registry.Apply(fset, interfaces); err != nil {
+			return nil, err
+		}
+	}
+	return interfaces, nil
+}
+
+func mustParseSource(t *testing.T, src string, option ParseOption, registry *Registry) Interfaces {
+	t.Helper()
+
+	interfaces, err := parseSource(t, src, option, registry)
+	if err != nil {
+		t.Fatalf("failed to parse interfaces: %v", err)
+	}
+	return interfaces
+}
+
+func TestResolvePackagePath(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n\ngo 1.23\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		param ParseParam
+		want  string
+	}{
+		{
+			name:  "module root",
+			param: ParseParam{TargetFile: filepath.Join(root, "foo.go")},
+			want:  "example.com/app",
+		},
+		{
+			name:  "package in module",
+			param: ParseParam{TargetFile: filepath.Join(root, "internal", "service", "foo.go")},
+			want:  "example.com/app/internal/service",
+		},
+		{
+			name:  "interface package path",
+			param: ParseParam{TargetFile: filepath.Join(root, "foo.go"), InterfacePackagePath: "example.com/other/service"},
+			want:  "example.com/other/service",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolvePackagePath(tt.param, "service"); got != tt.want {
+				t.Fatalf("resolvePackagePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
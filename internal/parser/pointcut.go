@@ -0,0 +1,207 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"path"
+	"strings"
+)
+
+const (
+	matchSeparator      = "."
+	matchSegmentCount   = 3
+	paramsSeparator     = ","
+	paramsRestToken     = "..."
+	annotateTokenPrefix = string(annotationToken)
+)
+
+// bracketEscaper escapes the brackets of the type, which are character classes of path.Match.
+var bracketEscaper = strings.NewReplacer("[", `\[`, "]", `\]`)
+
+// Rule attaches annotations to the methods that match the pointcut
+// without editing the interface.
+//
+//	match: "service.*.Create*"
+//	params: "context.Context, ..."
+//	annotate: [transactional, audit]
+//
+// match is "{package}.{interface}.{method}" and each segment is a glob pattern of path.Match.
+// params is a comma separated glob patterns of the parameter types.
+// commas in brackets are part of the type. e.g. "func(int, string) error"
+// brackets are matched literally, so "[]byte" matches the slice of byte.
+// "..." matches the rest of the parameters. empty params matches any parameters.
+// annotate is a list of annotations. arguments can be declared like "transactional(readOnly=true)".
+type Rule struct {
+	Match    string   `yaml:"match"`
+	Params   string   `yaml:"params"`
+	Annotate []string `yaml:"annotate"`
+}
+
+func (r Rule) validate() error {
+	segments := strings.Split(r.Match, matchSeparator)
+	if len(segments) != matchSegmentCount {
+		return fmt.Errorf("invalid match %q. must be {package}.{interface}.{method}", r.Match)
+	}
+
+	for _, segment := range append(segments, r.paramPatterns()...) {
+		if _, err := path.Match(segment, ""); err != nil {
+			return errors.Join(fmt.Errorf("invalid pattern %q", segment), err)
+		}
+	}
+
+	if len(r.Annotate) == 0 {
+		return fmt.Errorf("rule %q has no annotation", r.Match)
+	}
+
+	if _, err := r.annotations("", "", token.NoPos); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r Rule) paramPatterns() []string {
+	if strings.TrimSpace(r.Params) == "" {
+		return nil
+	}
+
+	patterns := []string{}
+	for _, pattern := range splitTopLevel(r.Params, paramsSeparator) {
+		patterns = append(patterns, bracketEscaper.Replace(strings.TrimSpace(pattern)))
+	}
+	return patterns
+}
+
+// splitTopLevel splits s by the separator that is not enclosed in brackets.
+// e.g. "func(int, string) error, map[string]int" to "func(int, string) error", " map[string]int"
+func splitTopLevel(s, separator string) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		}
+
+		if depth == 0 && strings.HasPrefix(s[i:], separator) {
+			parts = append(parts, s[start:i])
+			start = i + len(separator)
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func (r Rule) matches(packageName, interfaceName, methodName string, params Params) bool {
+	segments := strings.Split(r.Match, matchSeparator)
+	names := []string{packageName, interfaceName, methodName}
+	for i := range segments {
+		if ok, _ := path.Match(segments[i], names[i]); !ok {
+			return false
+		}
+	}
+
+	if strings.TrimSpace(r.Params) == "" {
+		return true
+	}
+	return matchParams(r.paramPatterns(), params)
+}
+
+func matchParams(patterns []string, params Params) bool {
+	for i, pattern := range patterns {
+		if pattern == paramsRestToken {
+			return true
+		}
+
+		if i >= len(params) {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern, params[i].Type); !ok {
+			return false
+		}
+	}
+	return len(patterns) == len(params)
+}
+
+func (r Rule) annotations(methodName, proxyTypeName string, pos token.Pos) (Annotations, error) {
+	annotations := Annotations{}
+	for _, annotate := range r.Annotate {
+		name, args, ok := parseAnnotationLine(annotateTokenPrefix+strings.TrimPrefix(annotate, annotateTokenPrefix), AnnotationSyntaxAt)
+		if !ok {
+			return nil, fmt.Errorf("invalid annotation %q on rule %q", annotate, r.Match)
+		}
+
+		// the middleware order is declared only on the method
+		if name == orderAnnotation {
+			return nil, fmt.Errorf("annotation %s can not be declared on rule %q", orderAnnotation, r.Match)
+		}
+
+		annotations = append(annotations, Annotation{
+			AnnotationName: name,
+			MethodName:     methodName,
+			ProxyTypeName:  proxyTypeName,
+			Arguments:      args,
+			pos:            pos,
+		})
+	}
+	return annotations, nil
+}
+
+type Rules []Rule
+
+func (r Rules) Validate() error {
+	for _, rule := range r {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge appends the annotations of the matched rules to the annotations declared on comment.
+// the annotation declared on comment takes precedence over the rules.
+func (r Rules) merge(annotations Annotations, packageName, interfaceName, methodName, proxyTypeName string, params Params, pos token.Pos) (Annotations, error) {
+	for _, rule := range r {
+		if !rule.matches(packageName, interfaceName, methodName, params) {
+			continue
+		}
+
+		ruleAnnotations, err := rule.annotations(methodName, proxyTypeName, pos)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, annotation := range ruleAnnotations {
+			if annotations.Exist(annotation.AnnotationName) {
+				continue
+			}
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations, nil
+}
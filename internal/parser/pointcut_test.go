@@ -0,0 +1,176 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRulesRejectOrder(t *testing.T) {
+	rules := Rules{{Match: "service.*.*", Annotate: []string{"retry", "order(retry, transactional)"}}}
+	if err := rules.Validate(); err == nil {
+		t.Fatal("Validate() must reject order declared on rule")
+	}
+}
+
+func TestBuiltinOrderIsPriority(t *testing.T) {
+	src := `package service
+
+import "context"
+
+type Foo interface {
+	// @timeout(1s)
+	// @retry
+	// @circuitbreaker
+	Create(ctx context.Context) error
+
+	// @timeout(1s)
+	// @retry
+	// @circuitbreaker
+	// @order(timeout, retry)
+	Update(ctx context.Context) error
+}
+`
+
+	interfaces := mustParseSource(t, src, ParseOption{}, NewRegistry())
+	want := []string{
+		"@retry -> @circuitbreaker -> @timeout",
+		"@timeout -> @retry -> @circuitbreaker",
+	}
+
+	for i, method := range interfaces[0].Methods {
+		if got := method.Annotations.Format(); got != want[i] {
+			t.Errorf("%s order = %q, want %q", method.Name, got, want[i])
+		}
+	}
+}
+
+func TestRuleParamPatterns(t *testing.T) {
+	tests := []struct {
+		params string
+		want   []string
+	}{
+		{params: "", want: nil},
+		{params: "context.Context, ...", want: []string{"context.Context", "..."}},
+		{params: "func(int, string) error, map[string]int", want: []string{"func(int, string) error", `map\[string\]int`}},
+		{params: "struct{a, b int}, []byte", want: []string{"struct{a, b int}", `\[\]byte`}},
+	}
+
+	for _, tt := range tests {
+		if got := (Rule{Params: tt.params}).paramPatterns(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("paramPatterns(%q) = %q, want %q", tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	params := Params{
+		{Type: "context.Context"},
+		{Type: "func(int, string) error"},
+		{Type: "[]byte"},
+	}
+
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{name: "any params", rule: Rule{Match: "service.*.Create*"}, want: true},
+		{name: "method mismatch", rule: Rule{Match: "service.*.Find*"}, want: false},
+		{name: "package mismatch", rule: Rule{Match: "repo.*.*"}, want: false},
+		{name: "rest", rule: Rule{Match: "*.*.*", Params: "context.Context, ..."}, want: true},
+		{name: "exact", rule: Rule{Match: "*.*.*", Params: "context.Context, func(int, string) error, []byte"}, want: true},
+		{name: "glob type", rule: Rule{Match: "*.*.*", Params: "*, func(*) error, []*"}, want: true},
+		{name: "too few patterns", rule: Rule{Match: "*.*.*", Params: "context.Context, func(int, string) error"}, want: false},
+		{name: "too many patterns", rule: Rule{Match: "*.*.*", Params: "context.Context, *, *, *"}, want: false},
+		{name: "type mismatch", rule: Rule{Match: "*.*.*", Params: "context.Context, func(int) error, ..."}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.matches("service", "Foo", "CreateFoo", params); got != tt.want {
+				t.Fatalf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		ok   bool
+	}{
+		{name: "valid", rule: Rule{Match: "service.*.*", Params: "[]byte, ...", Annotate: []string{"transactional(readOnly=true)"}}, ok: true},
+		{name: "segment count", rule: Rule{Match: "service.Create", Annotate: []string{"audit"}}},
+		{name: "bad pattern", rule: Rule{Match: "service.[.*", Annotate: []string{"audit"}}},
+		{name: "no annotation", rule: Rule{Match: "service.*.*"}},
+		{name: "invalid annotation", rule: Rule{Match: "service.*.*", Annotate: []string{"audit(readOnly"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.validate(); (err == nil) != tt.ok {
+				t.Fatalf("validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRulesMerge(t *testing.T) {
+	src := `package service
+
+import "context"
+
+type Foo interface {
+	// @transactional(readOnly=true)
+	Create(ctx context.Context, data []byte) error
+
+	Find(ctx context.Context, id int) error
+}
+`
+
+	option := ParseOption{
+		Rules: Rules{
+			{Match: "service.Foo.*", Params: "context.Context, []byte", Annotate: []string{"transactional", "audit"}},
+			{Match: "service.Foo.Find", Annotate: []string{"cache(ttl=10s)"}},
+		},
+	}
+
+	interfaces := mustParseSource(t, src, option, nil)
+	create, find := interfaces[0].Methods[0], interfaces[0].Methods[1]
+	if got := create.Annotations.Format(); got != "@transactional -> @audit" {
+		t.Fatalf("Create annotations = %q", got)
+	}
+
+	// the annotation declared on the comment takes precedence over the rules
+	annotation, _ := create.Annotations.Get("transactional")
+	if readOnly, _ := annotation.Arguments.Get("readOnly"); readOnly != "true" {
+		t.Fatalf("readOnly = %q, want true", readOnly)
+	}
+
+	if got := find.Annotations.Format(); got != "@cache" {
+		t.Fatalf("Find annotations = %q", got)
+	}
+}
@@ -0,0 +1,693 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	transactionalAnnotation  = "transactional"
+	readOnlyAnnotation       = "readonly"
+	sagaAnnotation           = "saga"
+	compensableAnnotation    = "compensable"
+	retryAnnotation          = "retry"
+	circuitBreakerAnnotation = "circuitbreaker"
+	rateLimitAnnotation      = "ratelimit"
+	bulkheadAnnotation       = "bulkhead"
+	timeoutAnnotation        = "timeout"
+
+	undoArgument = "undo"
+)
+
+type ArgumentType string
+
+const (
+	ArgumentTypeString   ArgumentType = "string"
+	ArgumentTypeBool     ArgumentType = "bool"
+	ArgumentTypeInt      ArgumentType = "int"
+	ArgumentTypeFloat    ArgumentType = "float"
+	ArgumentTypeDuration ArgumentType = "duration"
+
+	// ArgumentTypeIdent is a go identifier. e.g. name of sentinel error or method
+	ArgumentTypeIdent ArgumentType = "ident"
+
+	// ArgumentTypeEnum is one of the values declared on the argument schema.
+	ArgumentTypeEnum ArgumentType = "enum"
+)
+
+// ArgumentSchema declares an argument of the annotation.
+type ArgumentSchema struct {
+	Name     string       `yaml:"name"`
+	Type     ArgumentType `yaml:"type"`
+	Default  string       `yaml:"default"`
+	Values   []string     `yaml:"values"`
+	Required bool         `yaml:"required"`
+
+	// List accepts multiple values separated by "|". e.g. ErrNotFound|ErrAlreadyExists
+	List bool `yaml:"list"`
+}
+
+func (s ArgumentSchema) check(value string) error {
+	if !s.List {
+		return s.checkValue(value)
+	}
+
+	for _, v := range strings.Split(value, argumentValueSeparator) {
+		if err := s.checkValue(strings.TrimSpace(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s ArgumentSchema) checkValue(value string) error {
+	var err error
+	switch s.Type {
+	case "", ArgumentTypeString:
+	case ArgumentTypeBool:
+		_, err = strconv.ParseBool(value)
+	case ArgumentTypeInt:
+		_, err = strconv.Atoi(value)
+	case ArgumentTypeFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case ArgumentTypeDuration:
+		_, err = time.ParseDuration(value)
+	case ArgumentTypeIdent:
+		if !token.IsIdentifier(value) {
+			err = errors.New("not an identifier")
+		}
+	case ArgumentTypeEnum:
+		if _, ok := s.enumValue(value); !ok {
+			err = fmt.Errorf("must be one of %s", strings.Join(s.Values, ", "))
+		}
+	default:
+		err = fmt.Errorf("unknown argument type %q", s.Type)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s value %q for argument %s. %w", s.Type, value, s.Name, err)
+	}
+	return nil
+}
+
+// enumValue returns the declared value that equals the value case-insensitively.
+func (s ArgumentSchema) enumValue(value string) (string, bool) {
+	index := slices.IndexFunc(s.Values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+	if index < 0 {
+		return "", false
+	}
+	return s.Values[index], true
+}
+
+// normalize returns the value that the enum values are replaced with the declared values.
+// e.g. "requires_new" to "REQUIRES_NEW"
+func (s ArgumentSchema) normalize(value string) string {
+	if s.Type != ArgumentTypeEnum {
+		return value
+	}
+
+	values := []string{}
+	for _, v := range strings.Split(value, argumentValueSeparator) {
+		v = strings.TrimSpace(v)
+		if declared, ok := s.enumValue(v); ok {
+			v = declared
+		}
+		values = append(values, v)
+	}
+	return strings.Join(values, argumentValueSeparator)
+}
+
+// AnnotationSchema declares an annotation that is allowed on the method.
+type AnnotationSchema struct {
+	Name           string           `yaml:"name"`
+	Arguments      []ArgumentSchema `yaml:"arguments"`
+	RequireContext bool             `yaml:"requireContext"`
+	RequireError   bool             `yaml:"requireError"`
+
+	// Priority decides the middleware order among annotations of the method.
+	// the middleware of higher priority annotation wraps the others and runs first.
+	// annotations that have same priority run in declared order. nil is priority 0.
+	Priority *int `yaml:"priority"`
+}
+
+func (s AnnotationSchema) argument(name string) (ArgumentSchema, bool) {
+	for _, arg := range s.Arguments {
+		if arg.Name == name {
+			return arg, true
+		}
+	}
+	return ArgumentSchema{}, false
+}
+
+// merge returns the schema that the declared schema is merged over.
+// declared arguments replace the arguments of the same name and the others are appended.
+// the requirements can not be relaxed and the undeclared priority keeps the registered priority.
+func (s AnnotationSchema) merge(declared AnnotationSchema) AnnotationSchema {
+	merged := s
+	merged.Arguments = slices.Clone(s.Arguments)
+	for _, arg := range declared.Arguments {
+		index := slices.IndexFunc(merged.Arguments, func(a ArgumentSchema) bool {
+			return a.Name == arg.Name
+		})
+		if index < 0 {
+			merged.Arguments = append(merged.Arguments, arg)
+			continue
+		}
+		merged.Arguments[index] = arg
+	}
+
+	merged.RequireContext = s.RequireContext || declared.RequireContext
+	merged.RequireError = s.RequireError || declared.RequireError
+	if declared.Priority != nil {
+		merged.Priority = declared.Priority
+	}
+	return merged
+}
+
+func (s AnnotationSchema) validate() error {
+	if !isValidAnnotationName(s.Name) {
+		return fmt.Errorf("invalid annotation name %q", s.Name)
+	}
+
+	for _, arg := range s.Arguments {
+		if !isValidAnnotationName(arg.Name) {
+			return fmt.Errorf("invalid argument name %q on annotation %s", arg.Name, s.Name)
+		}
+
+		if arg.Type == ArgumentTypeEnum && len(arg.Values) == 0 {
+			return fmt.Errorf("enum argument %s on annotation %s has no values", arg.Name, s.Name)
+		}
+
+		if arg.Default == "" {
+			continue
+		}
+
+		if err := arg.check(arg.Default); err != nil {
+			return errors.Join(fmt.Errorf("invalid default on annotation %s", s.Name), err)
+		}
+	}
+	return nil
+}
+
+// Diagnostic is a violation of the annotation schema found at generation time.
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+func (d Diagnostic) Error() string {
+	return d.Pos.String() + ": " + d.Message
+}
+
+type Diagnostics []Diagnostic
+
+func (d Diagnostics) Error() string {
+	messages := []string{}
+	for _, diagnostic := range d {
+		messages = append(messages, diagnostic.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Registry holds the annotation schemas used to validate annotations at generation time.
+// annotations that have no schema are accepted as is unless the registry is strict.
+type Registry struct {
+	schemas map[string]AnnotationSchema
+	strict  bool
+}
+
+// NewRegistry returns a registry with built-in annotation schemas.
+func NewRegistry() *Registry {
+	r := &Registry{
+		schemas: map[string]AnnotationSchema{},
+	}
+
+	for _, s := range builtinSchemas() {
+		r.schemas[s.Name] = s
+	}
+	return r
+}
+
+// priorities of the built-in annotations in the recommended order from outermost.
+// @retry wraps @transactional, so it re-runs the whole transaction instead of
+// being skipped in the transaction.
+const (
+	sagaPriority           = 70
+	retryPriority          = 60
+	circuitBreakerPriority = 50
+	rateLimitPriority      = 40
+	bulkheadPriority       = 30
+	timeoutPriority        = 20
+	transactionalPriority  = 10
+)
+
+func priorityOf(priority int) *int {
+	return &priority
+}
+
+func builtinSchemas() []AnnotationSchema {
+	return []AnnotationSchema{
+		{
+			Name:     transactionalAnnotation,
+			Priority: priorityOf(transactionalPriority),
+			Arguments: []ArgumentSchema{
+				{
+					Name:    "propagation",
+					Type:    ArgumentTypeEnum,
+					Default: "REQUIRED",
+					Values:  []string{"REQUIRED", "REQUIRES_NEW", "NESTED", "SUPPORTS", "MANDATORY", "NOT_SUPPORTED", "NEVER"},
+				},
+				{
+					Name:    "isolation",
+					Type:    ArgumentTypeEnum,
+					Default: "DEFAULT",
+					Values:  []string{"DEFAULT", "READ_UNCOMMITTED", "READ_COMMITTED", "WRITE_COMMITTED", "REPEATABLE_READ", "SNAPSHOT", "SERIALIZABLE", "LINEARIZABLE"},
+				},
+				{
+					Name:    "readOnly",
+					Type:    ArgumentTypeBool,
+					Default: "false",
+				},
+				{
+					Name: "timeout",
+					Type: ArgumentTypeDuration,
+				},
+				{
+					Name: "rollbackFor",
+					Type: ArgumentTypeIdent,
+					List: true,
+				},
+				{
+					Name: "noRollbackFor",
+					Type: ArgumentTypeIdent,
+					List: true,
+				},
+				{
+					Name: "manager",
+					Type: ArgumentTypeIdent,
+					List: true,
+				},
+				{
+					Name: "retry",
+					Type: ArgumentTypeInt,
+				},
+				{
+					Name: "backoff",
+					Type: ArgumentTypeDuration,
+				},
+			},
+			RequireContext: true,
+			RequireError:   true,
+		},
+		{
+			Name:           readOnlyAnnotation,
+			RequireContext: true,
+		},
+		{
+			Name:           sagaAnnotation,
+			Priority:       priorityOf(sagaPriority),
+			RequireContext: true,
+			RequireError:   true,
+		},
+		{
+			Name:     retryAnnotation,
+			Priority: priorityOf(retryPriority),
+			Arguments: []ArgumentSchema{
+				{
+					Name:    "max",
+					Type:    ArgumentTypeInt,
+					Default: "3",
+				},
+				{
+					Name:    "backoff",
+					Type:    ArgumentTypeEnum,
+					Values:  []string{"constant", "linear", "exponential"},
+					Default: "exponential",
+				},
+				{
+					Name:    "initial",
+					Type:    ArgumentTypeDuration,
+					Default: "100ms",
+				},
+				{
+					Name: "maxDelay",
+					Type: ArgumentTypeDuration,
+				},
+				{
+					Name:    "jitter",
+					Type:    ArgumentTypeBool,
+					Default: "false",
+				},
+				{
+					Name: "on",
+					Type: ArgumentTypeIdent,
+					List: true,
+				},
+			},
+			RequireContext: true,
+			RequireError:   true,
+		},
+		{
+			Name:     circuitBreakerAnnotation,
+			Priority: priorityOf(circuitBreakerPriority),
+			Arguments: []ArgumentSchema{
+				{
+					Name:    "failureRatio",
+					Type:    ArgumentTypeFloat,
+					Default: "0.5",
+				},
+				{
+					Name:    "minRequests",
+					Type:    ArgumentTypeInt,
+					Default: "20",
+				},
+				{
+					Name:    "openFor",
+					Type:    ArgumentTypeDuration,
+					Default: "30s",
+				},
+				{
+					Name:    "window",
+					Type:    ArgumentTypeDuration,
+					Default: "60s",
+				},
+				{
+					Name:    "halfOpenRequests",
+					Type:    ArgumentTypeInt,
+					Default: "1",
+				},
+			},
+			RequireError: true,
+		},
+		{
+			Name:     rateLimitAnnotation,
+			Priority: priorityOf(rateLimitPriority),
+			Arguments: []ArgumentSchema{
+				{
+					Name:     "rps",
+					Type:     ArgumentTypeFloat,
+					Required: true,
+				},
+				{
+					Name: "burst",
+					Type: ArgumentTypeInt,
+				},
+			},
+			RequireError: true,
+		},
+		{
+			Name:     bulkheadAnnotation,
+			Priority: priorityOf(bulkheadPriority),
+			Arguments: []ArgumentSchema{
+				{
+					Name:    "max",
+					Type:    ArgumentTypeInt,
+					Default: "10",
+				},
+				{
+					Name:    "queue",
+					Type:    ArgumentTypeInt,
+					Default: "0",
+				},
+				{
+					Name: "wait",
+					Type: ArgumentTypeDuration,
+				},
+			},
+			RequireError: true,
+		},
+		{
+			// the deadline context is passed to the target, so the method must have a context
+			Name:     timeoutAnnotation,
+			Priority: priorityOf(timeoutPriority),
+			Arguments: []ArgumentSchema{
+				{
+					Name:     "duration",
+					Type:     ArgumentTypeDuration,
+					Required: true,
+				},
+			},
+			RequireContext: true,
+			RequireError:   true,
+		},
+		{
+			Name: compensableAnnotation,
+			Arguments: []ArgumentSchema{
+				{
+					Name:     undoArgument,
+					Type:     ArgumentTypeIdent,
+					Required: true,
+				},
+			},
+			RequireContext: true,
+			RequireError:   true,
+		},
+	}
+}
+
+// Register registers the annotation schema.
+// the schema is merged over the registered schema that has the same name,
+// so a built-in annotation can be declared only to change its priority.
+func (r *Registry) Register(s AnnotationSchema) error {
+	s.Name = strings.ToLower(s.Name)
+	if s.Name == orderAnnotation {
+		return fmt.Errorf("annotation name %s is reserved", orderAnnotation)
+	}
+
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	if registered, ok := r.schemas[s.Name]; ok {
+		s = registered.merge(s)
+	}
+
+	r.schemas[s.Name] = s
+	return nil
+}
+
+// SetStrict rejects annotations that have no schema if strict is true.
+func (r *Registry) SetStrict(strict bool) {
+	r.strict = strict
+}
+
+func (r *Registry) Lookup(name string) (AnnotationSchema, bool) {
+	s, ok := r.schemas[name]
+	return s, ok
+}
+
+func (r *Registry) priority(name string) int {
+	if priority := r.schemas[name].Priority; priority != nil {
+		return *priority
+	}
+	return 0
+}
+
+// Apply validates annotations of the interfaces.
+// arguments of valid annotations are normalized. positional arguments are
+// named by the order of the schema and omitted arguments are set to the default.
+func (r *Registry) Apply(fset *token.FileSet, interfaces Interfaces) error {
+	diagnostics := Diagnostics{}
+	for i := range interfaces {
+		for j := range interfaces[i].Methods {
+			method := &interfaces[i].Methods[j]
+			if method.order != nil {
+				for _, message := range r.applyOrder(method) {
+					diagnostics = append(diagnostics, Diagnostic{
+						Pos:     fset.Position(method.order.pos),
+						Message: fmt.Sprintf("%s.%s: @%s: %s", interfaces[i].InterfaceName, method.Name, orderAnnotation, message),
+					})
+				}
+			}
+
+			for k := range method.Annotations {
+				annotation := &method.Annotations[k]
+				for _, message := range r.apply(method, annotation) {
+					diagnostics = append(diagnostics, Diagnostic{
+						Pos:     fset.Position(annotation.pos),
+						Message: fmt.Sprintf("%s.%s: @%s: %s", interfaces[i].InterfaceName, method.Name, annotation.AnnotationName, message),
+					})
+				}
+			}
+
+			method.sortAnnotations(r.priority)
+		}
+
+		diagnostics = append(diagnostics, applyCompensation(fset, &interfaces[i])...)
+		interfaces[i].AllAnnotations = interfaces[i].Methods.AllAnnotations()
+	}
+
+	if len(diagnostics) != 0 {
+		slices.SortStableFunc(diagnostics, func(a, b Diagnostic) int {
+			return a.Pos.Offset - b.Pos.Offset
+		})
+		return diagnostics
+	}
+	return nil
+}
+
+// applyCompensation resolves the compensation methods of the compensable methods.
+// the compensation method is declared on the same type, takes the same parameters
+// and returns only an error.
+func applyCompensation(fset *token.FileSet, iface *Interface) Diagnostics {
+	diagnostics := Diagnostics{}
+	for i := range iface.Methods {
+		method := &iface.Methods[i]
+		annotation, ok := method.Annotations.Get(compensableAnnotation)
+		if !ok {
+			continue
+		}
+
+		undo, ok := annotation.Arguments.Get(undoArgument)
+		if !ok {
+			continue
+		}
+
+		if message := checkCompensation(iface.Methods, method, undo); message != "" {
+			diagnostics = append(diagnostics, Diagnostic{
+				Pos:     fset.Position(annotation.pos),
+				Message: fmt.Sprintf("%s.%s: @%s: %s", iface.InterfaceName, method.Name, compensableAnnotation, message),
+			})
+			continue
+		}
+		method.Compensation = undo
+	}
+	return diagnostics
+}
+
+func checkCompensation(methods Methods, method *Method, undo string) string {
+	index := slices.IndexFunc(methods, func(m Method) bool {
+		return m.Name == undo
+	})
+	if index < 0 {
+		return fmt.Sprintf("compensation method %s is not declared", undo)
+	}
+
+	compensation := methods[index]
+	if compensation.Name == method.Name {
+		return "method can not compensate itself"
+	}
+
+	sameParams := slices.EqualFunc(compensation.params, method.params, func(a, b Param) bool {
+		return a.Type == b.Type
+	})
+	if !sameParams {
+		return fmt.Sprintf("compensation method %s must have the same parameters as the method", undo)
+	}
+
+	if compensation.ResultTypes != errorType {
+		return fmt.Sprintf("compensation method %s must return only error", undo)
+	}
+	return ""
+}
+
+func (r *Registry) applyOrder(method *Method) []string {
+	messages := []string{}
+	for _, arg := range method.order.Arguments {
+		if arg.Key != positionalArgumentKey {
+			messages = append(messages, fmt.Sprintf("unknown argument %s", arg.Key))
+			continue
+		}
+
+		if !method.Annotations.Exist(arg.Value) {
+			messages = append(messages, fmt.Sprintf("annotation %s is not declared on the method", arg.Value))
+		}
+	}
+	return messages
+}
+
+func (r *Registry) apply(method *Method, annotation *Annotation) []string {
+	s, ok := r.Lookup(annotation.AnnotationName)
+	if !ok {
+		if r.strict {
+			return []string{"unknown annotation"}
+		}
+		return nil
+	}
+
+	messages := []string{}
+	if s.RequireContext && !method.HasContext {
+		messages = append(messages, "method must have a context.Context parameter")
+	}
+
+	if s.RequireError && !method.HasError {
+		messages = append(messages, "method must have an error result")
+	}
+
+	args := Arguments{}
+	for i, arg := range annotation.Arguments {
+		key := arg.Key
+		if key == positionalArgumentKey {
+			if i >= len(s.Arguments) {
+				messages = append(messages, fmt.Sprintf("too many arguments. expected at most %d", len(s.Arguments)))
+				continue
+			}
+			key = s.Arguments[i].Name
+		}
+
+		argSchema, ok := s.argument(key)
+		if !ok {
+			messages = append(messages, fmt.Sprintf("unknown argument %s", key))
+			continue
+		}
+
+		if _, exist := args.Get(key); exist {
+			messages = append(messages, fmt.Sprintf("duplicated argument %s", key))
+			continue
+		}
+
+		if err := argSchema.check(arg.Value); err != nil {
+			messages = append(messages, err.Error())
+			continue
+		}
+
+		args = append(args, Argument{Key: key, Value: argSchema.normalize(arg.Value)})
+	}
+
+	for _, argSchema := range s.Arguments {
+		if _, exist := args.Get(argSchema.Name); exist {
+			continue
+		}
+
+		if argSchema.Required {
+			messages = append(messages, fmt.Sprintf("missing required argument %s", argSchema.Name))
+			continue
+		}
+
+		if argSchema.Default != "" {
+			args = append(args, Argument{Key: argSchema.Name, Value: argSchema.Default})
+		}
+	}
+
+	annotation.Arguments = args
+	return messages
+}
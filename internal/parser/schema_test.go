@@ -0,0 +1,259 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const schemaSource = `package service
+
+import "context"
+
+type Foo interface {
+	// @retry
+	// @transactional(readOnly=true, propagation=REQUIRES_NEW)
+	Create(ctx context.Context) error
+}
+`
+
+func TestRegistryRegisterMergesBuiltin(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetStrict(true)
+	if err := registry.Register(AnnotationSchema{Name: "Transactional", Priority: priorityOf(100)}); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+
+	s, _ := registry.Lookup(transactionalAnnotation)
+	if *s.Priority != 100 || !s.RequireContext || !s.RequireError {
+		t.Fatalf("merged schema = %+v", s)
+	}
+
+	interfaces := mustParseSource(t, schemaSource, ParseOption{}, registry)
+	method := interfaces[0].Methods[0]
+	if got := method.Annotations.Format(); got != "@transactional -> @retry" {
+		t.Fatalf("order = %q", got)
+	}
+
+	annotation, _ := method.Annotations.Get(transactionalAnnotation)
+	if readOnly, _ := annotation.Arguments.Get("readOnly"); readOnly != "true" {
+		t.Fatalf("readOnly = %q, want true", readOnly)
+	}
+}
+
+func TestRegistryBuiltinPriority(t *testing.T) {
+	const source = `package service
+
+import "context"
+
+type Foo interface {
+	// @transactional
+	// @timeout(1s)
+	// @bulkhead
+	// @ratelimit(10)
+	// @circuitbreaker
+	// @retry
+	// @saga
+	Create(ctx context.Context) error
+}
+`
+
+	tests := []struct {
+		name    string
+		schemas []AnnotationSchema
+		want    string
+	}{
+		{
+			name: "recommended order",
+			want: "@saga -> @retry -> @circuitbreaker -> @ratelimit -> @bulkhead -> @timeout -> @transactional",
+		},
+		{
+			name: "undeclared priority keeps the builtin priority",
+			schemas: []AnnotationSchema{
+				{Name: retryAnnotation, RequireContext: true},
+			},
+			want: "@saga -> @retry -> @circuitbreaker -> @ratelimit -> @bulkhead -> @timeout -> @transactional",
+		},
+		{
+			name: "reset to zero priority",
+			schemas: []AnnotationSchema{
+				{Name: retryAnnotation, Priority: priorityOf(0)},
+				{Name: sagaAnnotation, Priority: priorityOf(0)},
+			},
+			want: "@circuitbreaker -> @ratelimit -> @bulkhead -> @timeout -> @transactional -> @retry -> @saga",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			for _, s := range tt.schemas {
+				if err := registry.Register(s); err != nil {
+					t.Fatalf("Register() = %v", err)
+				}
+			}
+
+			interfaces := mustParseSource(t, source, ParseOption{}, registry)
+			if got := interfaces[0].Methods[0].Annotations.Format(); got != tt.want {
+				t.Fatalf("order = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryRegisterReplacesArgument(t *testing.T) {
+	registry := NewRegistry()
+	err := registry.Register(AnnotationSchema{
+		Name: retryAnnotation,
+		Arguments: []ArgumentSchema{
+			{Name: "max", Type: ArgumentTypeInt, Default: "5"},
+			{Name: "label", Type: ArgumentTypeString},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+
+	s, _ := registry.Lookup(retryAnnotation)
+	max, _ := s.argument("max")
+	if max.Default != "5" {
+		t.Fatalf("max default = %q, want 5", max.Default)
+	}
+
+	if _, ok := s.argument("label"); !ok {
+		t.Fatal("label argument is not appended")
+	}
+
+	if _, ok := s.argument("backoff"); !ok {
+		t.Fatal("builtin backoff argument is dropped")
+	}
+
+	builtin, _ := NewRegistry().Lookup(retryAnnotation)
+	if max, _ := builtin.argument("max"); max.Default == "5" {
+		t.Fatal("merge modified the builtin schema")
+	}
+}
+
+func TestRegistryRegisterReservedName(t *testing.T) {
+	if err := NewRegistry().Register(AnnotationSchema{Name: "order"}); err == nil {
+		t.Fatal("Register(order) must fail")
+	}
+}
+
+func TestRegistryApplyDiagnostics(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		strict bool
+		want   string
+	}{
+		{
+			name:   "unknown annotation on strict",
+			src:    "// @cahce\n\tFind(ctx context.Context) error",
+			strict: true,
+			want:   "source.go:6:2: Foo.Find: @cahce: unknown annotation",
+		},
+		{
+			name: "missing context",
+			src:  "// @transactional\n\tFind() error",
+			want: "@transactional: method must have a context.Context parameter",
+		},
+		{
+			name: "missing context of timeout",
+			src:  "// @timeout(800ms)\n\tFind() error",
+			want: "@timeout: method must have a context.Context parameter",
+		},
+		{
+			name: "invalid enum",
+			src:  "// @transactional(propagation=SOMETIMES)\n\tFind(ctx context.Context) error",
+			want: "invalid enum value \"SOMETIMES\" for argument propagation",
+		},
+		{
+			name: "unknown argument",
+			src:  "// @retry(times=3)\n\tFind(ctx context.Context) error",
+			want: "@retry: unknown argument times",
+		},
+		{
+			name: "missing required argument",
+			src:  "// @ratelimit\n\tFind(ctx context.Context) error",
+			want: "@ratelimit: missing required argument rps",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			registry.SetStrict(tt.strict)
+
+			src := "package service\n\nimport \"context\"\n\ntype Foo interface {\n\t" + tt.src + "\n}\n"
+			_, err := parseSource(t, src, ParseOption{}, registry)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Apply() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryApplyNormalizesEnum(t *testing.T) {
+	src := `package service
+
+import "context"
+
+type Foo interface {
+	// @transactional(propagation=requires_new, isolation=Serializable)
+	// @retry(backoff=LINEAR)
+	Create(ctx context.Context) error
+}
+`
+
+	interfaces := mustParseSource(t, src, ParseOption{}, NewRegistry())
+	method := interfaces[0].Methods[0]
+	tests := []struct {
+		annotation string
+		key        string
+		want       string
+	}{
+		{annotation: transactionalAnnotation, key: "propagation", want: "REQUIRES_NEW"},
+		{annotation: transactionalAnnotation, key: "isolation", want: "SERIALIZABLE"},
+		{annotation: retryAnnotation, key: "backoff", want: "linear"},
+	}
+
+	for _, tt := range tests {
+		annotation, _ := method.Annotations.Get(tt.annotation)
+		if got, _ := annotation.Arguments.Get(tt.key); got != tt.want {
+			t.Errorf("@%s(%s) = %q, want %q", tt.annotation, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestArgumentSchemaNormalizeList(t *testing.T) {
+	s := ArgumentSchema{Name: "mode", Type: ArgumentTypeEnum, Values: []string{"read", "write"}, List: true}
+	if err := s.check("READ | Write"); err != nil {
+		t.Fatalf("check() = %v", err)
+	}
+
+	if got := s.normalize("READ | Write"); got != "read|write" {
+		t.Fatalf("normalize() = %q, want read|write", got)
+	}
+}
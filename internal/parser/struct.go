@@ -0,0 +1,336 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	extractedInterfaceSuffix = "Interface"
+)
+
+// majorVersionPattern matches the major version suffix of the import path. e.g. v2
+var majorVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// parseStructType parses the structs that have annotated methods.
+// the methods are collected from the method declarations of the file and
+// the other files of the package. only exported methods are proxied.
+//
+//	// @transactional
+//	func (s *FooService) Create(ctx context.Context, foo Foo) error
+func parseStructType(node *ast.File, isDiffrentPackage bool, option ParseOption) ([]Interface, error) {
+	if ast.IsGenerated(node) {
+		return nil, nil
+	}
+
+	pkg := newStructPackage(node, option)
+	structs := []Interface{}
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok || typeSpec.TypeParams != nil {
+				continue
+			}
+
+			if _, ok := typeSpec.Type.(*ast.StructType); !ok {
+				continue
+			}
+
+			if isDiffrentPackage && !typeSpec.Name.IsExported() {
+				continue
+			}
+
+			s := Interface{
+				ProxyTypeName:     typeSpec.Name.Name + proxySuffix,
+				InterfaceName:     typeSpec.Name.Name,
+				InterfacePackage:  node.Name.Name,
+				IsDiffrentPackage: isDiffrentPackage,
+				IsStruct:          true,
+			}
+
+			if option.ExtractInterface {
+				s.ExtractedInterfaceName = typeSpec.Name.Name + extractedInterfaceSuffix
+			}
+
+			methods, imports, err := parseStructMethod(s, pkg, option)
+			if err != nil {
+				return nil, err
+			}
+
+			// the struct is proxied only if any method is annotated by its declaration or the rules
+			if !methods.annotated() {
+				continue
+			}
+
+			s.Methods = methods
+			s.AllAnnotations = s.Methods.AllAnnotations()
+			s.imports = imports
+			structs = append(structs, s)
+		}
+	}
+
+	return structs, nil
+}
+
+// structPackage indexes the declarations of the package to resolve the method set of the struct.
+type structPackage struct {
+	node    *ast.File
+	types   map[string]*ast.TypeSpec
+	methods map[string][]structMethodDecl
+}
+
+type structMethodDecl struct {
+	decl *ast.FuncDecl
+	file *ast.File
+}
+
+func newStructPackage(node *ast.File, option ParseOption) structPackage {
+	files := []*ast.File{node}
+	for _, file := range option.Files {
+		if file != node && file.Name.Name == node.Name.Name && !ast.IsGenerated(file) {
+			files = append(files, file)
+		}
+	}
+
+	pkg := structPackage{
+		node:    node,
+		types:   map[string]*ast.TypeSpec{},
+		methods: map[string][]structMethodDecl{},
+	}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+						pkg.types[typeSpec.Name.Name] = typeSpec
+					}
+				}
+			case *ast.FuncDecl:
+				if name := receiverTypeName(decl); name != "" {
+					pkg.methods[name] = append(pkg.methods[name], structMethodDecl{decl: decl, file: file})
+				}
+			}
+		}
+	}
+	return pkg
+}
+
+// parseStructMethod parses the exported methods of the method set of the struct.
+// the methods promoted from the embedded types declared in the package are included
+// by the selector rules of go. a shallower name shadows the deeper names and
+// the names declared twice on the same depth are not promoted.
+// it also returns the imports used by the methods declared on the other files.
+// the embedded fields that can not be resolved in the package are rejected
+// only if the struct is proxied.
+func parseStructMethod(s Interface, pkg structPackage, option ParseOption) (Methods, []Import, error) {
+	methods := Methods{}
+	imports := []Import{}
+	var embedErr error
+
+	shadowed := map[string]bool{}
+	visited := map[string]bool{s.InterfaceName: true}
+	level := []string{s.InterfaceName}
+	for len(level) != 0 {
+		declared := map[string]int{}
+		candidates := []structMethodDecl{}
+		next := []string{}
+		for _, typeName := range level {
+			for _, m := range pkg.methods[typeName] {
+				declared[m.decl.Name.Name]++
+				candidates = append(candidates, m)
+			}
+
+			structType, ok := pkg.types[typeName].Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			for _, field := range structType.Fields.List {
+				for _, name := range field.Names {
+					declared[name.Name]++
+				}
+
+				if len(field.Names) != 0 {
+					continue
+				}
+
+				embedded, err := pkg.embeddedTypeName(s, field.Type)
+				if err != nil {
+					embedErr = err
+					continue
+				}
+
+				declared[embedded]++
+				if !visited[embedded] {
+					visited[embedded] = true
+					next = append(next, embedded)
+				}
+			}
+		}
+
+		for _, m := range candidates {
+			name := m.decl.Name.Name
+			if !m.decl.Name.IsExported() || shadowed[name] || declared[name] > 1 {
+				continue
+			}
+
+			method, err := parseFuncMethod(s, name, m.decl.Doc, m.decl.Type, m.decl.Pos(), option)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			methods = append(methods, method)
+			if m.file != pkg.node {
+				imports = appendImports(imports, usedImports(m.file, m.decl.Type))
+			}
+		}
+
+		for name := range declared {
+			shadowed[name] = true
+		}
+		level = next
+	}
+
+	if embedErr != nil && methods.annotated() {
+		return nil, nil, embedErr
+	}
+	return methods, imports, nil
+}
+
+// embeddedTypeName returns the name of the embedded type declared in the package.
+// the embedded types of the other packages and the embedded interfaces are rejected
+// because their methods can not be resolved from the declarations of the package.
+func (pkg structPackage) embeddedTypeName(s Interface, expr ast.Expr) (string, error) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", fmt.Errorf("struct %s embeds %s. promoted methods of the embedded type of the other package or the generic type can not be proxied", s.InterfaceName, exprToString(expr))
+	}
+
+	typeSpec, ok := pkg.types[ident.Name]
+	if !ok {
+		return "", fmt.Errorf("struct %s embeds %s that is not declared in the package. its promoted methods can not be proxied", s.InterfaceName, ident.Name)
+	}
+
+	if _, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+		return "", fmt.Errorf("struct %s embeds interface %s. promoted methods of the embedded interface can not be proxied", s.InterfaceName, ident.Name)
+	}
+	return ident.Name, nil
+}
+
+// usedImports returns the imports of the file referred by the types of the function.
+// the import that has no alias is matched by the last element of the path and
+// the imports can not be matched are kept to be resolved by goimports.
+func usedImports(file *ast.File, funcType *ast.FuncType) []Import {
+	fileImports, _ := ParseImportPackage(file)
+	imports := []Import{}
+	ast.Inspect(funcType, func(n ast.Node) bool {
+		selector, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := selector.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		matched := []Import{}
+		unknown := []Import{}
+		for _, i := range fileImports {
+			switch {
+			case i.Alias == ident.Name:
+				matched = append(matched, i)
+			case i.Alias == "" && importName(i.Path) == ident.Name:
+				matched = append(matched, i)
+			case i.Alias == "":
+				unknown = append(unknown, i)
+			}
+		}
+
+		if len(matched) == 0 {
+			matched = unknown
+		}
+		imports = appendImports(imports, matched)
+		return false
+	})
+	return imports
+}
+
+// importName guesses the package name from the import path.
+// e.g. "github.com/jackc/pgx/v5" to "pgx", "gopkg.in/yaml.v3" to "yaml"
+func importName(importPath string) string {
+	name := path.Base(importPath)
+	if majorVersionPattern.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+
+	name, _, _ = strings.Cut(name, ".")
+	return strings.TrimPrefix(name, "go-")
+}
+
+// appendImports appends the imports that are not imported yet.
+func appendImports(imports []Import, other []Import) []Import {
+	for _, i := range other {
+		if !slices.ContainsFunc(imports, func(imported Import) bool { return imported.Path == i.Path }) {
+			imports = append(imports, i)
+		}
+	}
+	return imports
+}
+
+// receiverTypeName returns the base type name of the method receiver.
+// e.g. FooService of (s *FooService)
+func receiverTypeName(funcDecl *ast.FuncDecl) string {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+		return ""
+	}
+
+	expr := funcDecl.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	return ident.Name
+}
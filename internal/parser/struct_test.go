@@ -0,0 +1,211 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"go/ast"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStructProxyGolden(t *testing.T) {
+	assertGolden(t, "struct", ParseParam{ExtractInterface: true})
+}
+
+func methodNames(methods Methods) []string {
+	names := []string{}
+	for _, m := range methods {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+func TestParseStructMethodSet(t *testing.T) {
+	src := `package service
+
+import "context"
+
+type Left struct{}
+
+func (Left) Find(ctx context.Context) error { return nil }
+func (Left) Count() int { return 0 }
+
+type Right struct{}
+
+func (*Right) Find(ctx context.Context) error { return nil }
+func (*Right) Close() error { return nil }
+
+type Inner struct{ Left }
+
+type Foo struct {
+	Inner
+	*Right
+	Count int
+}
+
+// @transactional
+func (f *Foo) Create(ctx context.Context) error { return nil }
+
+func (f *Foo) unexported() {}
+`
+
+	interfaces := mustParseSource(t, src, ParseOption{}, nil)
+	if len(interfaces) != 1 || interfaces[0].InterfaceName != "Foo" {
+		t.Fatalf("interfaces = %v", interfaces.Names())
+	}
+
+	// Count is shadowed by the field and Find of Left is deeper than Find of Right
+	want := []string{"Create", "Find", "Close"}
+	if got := methodNames(interfaces[0].Methods); !reflect.DeepEqual(got, want) {
+		t.Fatalf("methods = %v, want %v", got, want)
+	}
+}
+
+func TestParseStructAmbiguousMethod(t *testing.T) {
+	src := `package service
+
+import "context"
+
+type Left struct{}
+
+func (Left) Find(ctx context.Context) error { return nil }
+
+type Right struct{}
+
+func (Right) Find(ctx context.Context) error { return nil }
+
+type Foo struct {
+	Left
+	Right
+}
+
+// @transactional
+func (f *Foo) Create(ctx context.Context) error { return nil }
+`
+
+	interfaces := mustParseSource(t, src, ParseOption{}, nil)
+	if got := methodNames(interfaces[0].Methods); !reflect.DeepEqual(got, []string{"Create"}) {
+		t.Fatalf("methods = %v, want only Create", got)
+	}
+}
+
+func TestParseStructRules(t *testing.T) {
+	src := `package service
+
+import "context"
+
+type Foo struct{}
+
+func (f *Foo) Create(ctx context.Context) error { return nil }
+
+type Bar struct{}
+
+func (b *Bar) Create(ctx context.Context) error { return nil }
+`
+
+	option := ParseOption{Rules: Rules{{Match: "service.Foo.*", Annotate: []string{"transactional"}}}}
+	interfaces := mustParseSource(t, src, option, NewRegistry())
+	if got := interfaces.Names(); !reflect.DeepEqual(got, []string{"Foo"}) {
+		t.Fatalf("interfaces = %v, want Foo proxied by the rule", got)
+	}
+
+	if got := interfaces[0].Methods[0].Annotations.Format(); got != "@transactional" {
+		t.Fatalf("annotations = %q", got)
+	}
+}
+
+func TestParseStructRejectsUnresolvedEmbedding(t *testing.T) {
+	tests := []struct {
+		name  string
+		embed string
+		want  string
+	}{
+		{name: "other package", embed: "sync.Mutex", want: "struct Foo embeds sync.Mutex"},
+		{name: "interface", embed: "Closer", want: "struct Foo embeds interface Closer"},
+		{name: "undeclared", embed: "error", want: "struct Foo embeds error that is not declared"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := `package service
+
+import (
+	"context"
+	"sync"
+)
+
+type Closer interface{ Close() error }
+
+var _ sync.Locker
+
+type Foo struct {
+	` + tt.embed + `
+}
+
+// @transactional
+func (f *Foo) Create(ctx context.Context) error { return nil }
+
+type Bar struct {
+	` + tt.embed + `
+}
+
+func (b *Bar) Create(ctx context.Context) error { return nil }
+`
+
+			_, err := parseSource(t, src, ParseOption{}, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("ParseInterface() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsedImports(t *testing.T) {
+	src := `package service
+
+import (
+	"context"
+	"strings"
+	pgx "github.com/jackc/pgx/v5"
+	"gopkg.in/yaml.v3"
+	"github.com/acme/go-client"
+	"github.com/acme/weird"
+)
+
+func F(ctx context.Context, conn pgx.Conn, node yaml.Node, c client.Client, w wrd.Thing) {}
+`
+
+	file := parseFile(t, src)
+	funcType := file.Decls[1].(*ast.FuncDecl).Type
+	got := []string{}
+	for _, i := range usedImports(file, funcType) {
+		got = append(got, i.Path)
+	}
+
+	// wrd can not be matched, so the unmatched imports are left to goimports
+	want := []string{"context", "github.com/jackc/pgx/v5", "gopkg.in/yaml.v3", "github.com/acme/go-client", "strings", "github.com/acme/weird"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("imports = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,21 @@
+﻿package service
+
+import (
+	"context"
+	"net/http"
+)
+
+// @timeout(1s)
+// @audit
+type Handler func(ctx context.Context, req *http.Request) (*http.Response, error)
+
+type (
+	// @audit
+	listener func(event string)
+
+	// not annotated
+	Callback func() error
+)
+
+// @audit
+type Mapper[T any] func(T) T
@@ -0,0 +1,29 @@
+﻿package service
+
+import (
+	"context"
+	"io"
+)
+
+type Order struct{ ID int }
+
+type OrderService interface {
+	// @saga
+	Place(ctx context.Context, order Order) error
+
+	// @compensable(undo=Cancel)
+	// @retry(max=2)
+	// @timeout(500ms)
+	// @order(timeout, retry)
+	Reserve(ctx context.Context, order Order) (int, error)
+
+	Cancel(ctx context.Context, order Order) error
+
+	//proxy:audit level=info
+	Notify(ctx context.Context, w io.Writer, lines ...string)
+
+	// @audit
+	Lookup(context.Context, int, string) (p Order, f bool, err error)
+
+	Count() int
+}
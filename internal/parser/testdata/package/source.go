@@ -0,0 +1,12 @@
+﻿package service
+
+import "context"
+
+type Foo struct{ ID int }
+
+type FooRepository interface {
+	// @transactional(readOnly=true)
+	Find(ctx context.Context, id int) (Foo, error)
+
+	Save(ctx context.Context, foo *Foo) error
+}
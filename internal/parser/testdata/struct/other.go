@@ -0,0 +1,15 @@
+﻿package service
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// @timeout(1s)
+func (s *FooService) Wait(ctx context.Context, d time.Duration) error { return nil }
+
+func jitter(s string) string {
+	return strings.Repeat(s, rand.Intn(3))
+}
@@ -0,0 +1,26 @@
+﻿package service
+
+import (
+	"context"
+	"sync"
+)
+
+type Base struct{}
+
+// @audit
+func (b *Base) Ping(ctx context.Context) error { return nil }
+
+func (b *Base) Name() string { return "base" }
+
+type FooService struct {
+	*Base
+	mu sync.Mutex
+}
+
+// @transactional
+func (s *FooService) Create(ctx context.Context, id int) (int, error) { return id, nil }
+
+// Name shadows the name of Base
+func (s *FooService) Name() string { return "foo" }
+
+func (s *FooService) lock() { s.mu.Lock() }
@@ -0,0 +1,314 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var errFailed = errors.New("failed")
+
+// adapterDB runs the queries through the adapter of the database package.
+type adapterDB struct {
+	factory TransactionFactory
+
+	// insert inserts the value through the transaction of the context or the db.
+	insert func(c context.Context, value string) error
+
+	// count counts the values through the transaction of the context or the db.
+	count func(c context.Context) (int, error)
+
+	// inTx reports whether the transaction of the context is found by the adapter.
+	inTx func(c context.Context) bool
+}
+
+const (
+	createTable = "CREATE TABLE foo (value TEXT NOT NULL)"
+	insertValue = "INSERT INTO foo (value) VALUES (?)"
+	countValues = "SELECT COUNT(*) FROM foo"
+)
+
+func openSQL(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db")+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec(createTable); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func sqlAdapter(t *testing.T) adapterDB {
+	db := openSQL(t)
+	return adapterDB{
+		factory: NewSQLTransactionFactory(db),
+		insert: func(c context.Context, value string) error {
+			_, err := SQLFromContext(c, db).ExecContext(c, insertValue, value)
+			return err
+		},
+		count: func(c context.Context) (int, error) {
+			n := 0
+			err := SQLFromContext(c, db).QueryRowContext(c, countValues).Scan(&n)
+			return n, err
+		},
+		inTx: func(c context.Context) bool {
+			_, ok := SQLTxFromContext(c, db)
+			return ok
+		},
+	}
+}
+
+func sqlxAdapter(t *testing.T) adapterDB {
+	db := sqlx.NewDb(openSQL(t), "sqlite3")
+	return adapterDB{
+		factory: NewSQLXTransactionFactory(db),
+		insert: func(c context.Context, value string) error {
+			_, err := SQLXFromContext(c, db).ExecContext(c, insertValue, value)
+			return err
+		},
+		count: func(c context.Context) (int, error) {
+			n := 0
+			err := sqlx.GetContext(c, SQLXFromContext(c, db), &n, countValues)
+			return n, err
+		},
+		inTx: func(c context.Context) bool {
+			_, ok := SQLXTxFromContext(c, db)
+			return ok
+		},
+	}
+}
+
+func gormAdapter(t *testing.T) adapterDB {
+	db, err := gorm.Open(sqlite.Dialector{Conn: openSQL(t)}, &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return adapterDB{
+		factory: NewGORMTransactionFactory(db),
+		insert: func(c context.Context, value string) error {
+			return GORMFromContext(c, db).Exec(insertValue, value).Error
+		},
+		count: func(c context.Context) (int, error) {
+			n := 0
+			err := GORMFromContext(c, db).Raw(countValues).Scan(&n).Error
+			return n, err
+		},
+		inTx: func(c context.Context) bool {
+			_, ok := GORMTxFromContext(c, db)
+			return ok
+		},
+	}
+}
+
+// transactional runs f by the middleware as the method annotated with @transactional(propagation).
+func transactional(c context.Context, m func(func(c context.Context) error) func(context.Context) error, propagation Propagation, f func(c context.Context) error) error {
+	inv := &invocation.Invocation{
+		Interface: "Foo",
+		Method:    "Create",
+		Annotations: []invocation.Annotation{
+			{Name: "transactional", Arguments: []invocation.Argument{{Key: "propagation", Value: string(propagation)}}},
+		},
+	}
+	return m(f)(invocation.WithContext(c, inv))
+}
+
+func assertCount(t *testing.T, db adapterDB, want int) {
+	t.Helper()
+
+	n, err := db.count(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != want {
+		t.Fatalf("count = %d, want %d", n, want)
+	}
+}
+
+func TestAdapter(t *testing.T) {
+	adapters := map[string]func(t *testing.T) adapterDB{
+		"sql":  sqlAdapter,
+		"sqlx": sqlxAdapter,
+		"gorm": gormAdapter,
+	}
+
+	for name, open := range adapters {
+		t.Run(name, func(t *testing.T) {
+			t.Run("commit", func(t *testing.T) {
+				db := open(t)
+				m := TxMiddleware(db.factory)
+				err := transactional(context.Background(), m, PropagationRequired, func(c context.Context) error {
+					if !db.inTx(c) {
+						return errors.New("transaction is not found from the context")
+					}
+					return db.insert(c, "a")
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertCount(t, db, 1)
+			})
+
+			t.Run("rollback", func(t *testing.T) {
+				db := open(t)
+				m := TxMiddleware(db.factory)
+				err := transactional(context.Background(), m, PropagationRequired, func(c context.Context) error {
+					if err := db.insert(c, "a"); err != nil {
+						return err
+					}
+					return errFailed
+				})
+				if !errors.Is(err, errFailed) {
+					t.Fatalf("err = %v, want %v", err, errFailed)
+				}
+				assertCount(t, db, 0)
+			})
+
+			t.Run("joined method sees the work of the transaction", func(t *testing.T) {
+				db := open(t)
+				m := TxMiddleware(db.factory)
+				err := transactional(context.Background(), m, PropagationRequired, func(c context.Context) error {
+					if err := db.insert(c, "a"); err != nil {
+						return err
+					}
+
+					return transactional(c, m, PropagationMandatory, func(c context.Context) error {
+						n, err := db.count(c)
+						if err != nil {
+							return err
+						}
+
+						if n != 1 {
+							return errors.New("joined method does not see the work of the transaction")
+						}
+						return nil
+					})
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertCount(t, db, 1)
+			})
+
+			t.Run("savepoint rollback", func(t *testing.T) {
+				db := open(t)
+				m := TxMiddleware(db.factory)
+				err := transactional(context.Background(), m, PropagationRequired, func(c context.Context) error {
+					if err := db.insert(c, "a"); err != nil {
+						return err
+					}
+
+					err := transactional(c, m, PropagationNested, func(c context.Context) error {
+						if err := db.insert(c, "b"); err != nil {
+							return err
+						}
+						return errFailed
+					})
+					if !errors.Is(err, errFailed) {
+						return errors.Join(errors.New("nested method must fail"), err)
+					}
+
+					return transactional(c, m, PropagationNested, func(c context.Context) error {
+						return db.insert(c, "c")
+					})
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertCount(t, db, 2)
+			})
+
+			t.Run("suspend", func(t *testing.T) {
+				db := open(t)
+				m := TxMiddleware(db.factory)
+				err := transactional(context.Background(), m, PropagationRequired, func(c context.Context) error {
+					if err := db.insert(c, "a"); err != nil {
+						return err
+					}
+
+					return transactional(c, m, PropagationNotSupported, func(c context.Context) error {
+						if db.inTx(c) {
+							return errors.New("transaction is not suspended")
+						}
+
+						// the uncommitted work of the suspended transaction is not visible
+						n, err := db.count(c)
+						if err != nil {
+							return err
+						}
+
+						if n != 0 {
+							return errors.New("suspended method sees the uncommitted work")
+						}
+						return nil
+					})
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertCount(t, db, 1)
+			})
+
+			t.Run("requires new suspends the transaction", func(t *testing.T) {
+				db := open(t)
+				m := TxMiddleware(db.factory)
+				err := transactional(context.Background(), m, PropagationRequired, func(c context.Context) error {
+					// sqlite allows one writer, so the new transaction writes before the outer one
+					err := transactional(c, m, PropagationRequiresNew, func(c context.Context) error {
+						return db.insert(c, "new")
+					})
+					if err != nil {
+						return err
+					}
+
+					if err := db.insert(c, "outer"); err != nil {
+						return err
+					}
+					return errFailed
+				})
+				if !errors.Is(err, errFailed) {
+					t.Fatalf("err = %v, want %v", err, errFailed)
+				}
+
+				// the work of the new transaction is committed independently of the outer one
+				assertCount(t, db, 1)
+			})
+		})
+	}
+}
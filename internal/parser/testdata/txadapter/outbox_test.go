@@ -0,0 +1,79 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const createOutbox = `CREATE TABLE outbox (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	topic        VARCHAR(255) NOT NULL,
+	payload      BLOB NOT NULL,
+	created_at   TIMESTAMP NOT NULL,
+	published_at TIMESTAMP NULL
+)`
+
+func TestSQLOutbox(t *testing.T) {
+	db := openSQL(t)
+	if _, err := db.Exec(createOutbox); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewSQLOutboxStore(db)
+	publisher := &MemoryPublisher{}
+	relay := NewRelay(store, publisher)
+	m := TxMiddlewareWithOptions(NewSQLTransactionFactory(db), WithOutbox(store))
+
+	err := transactional(context.Background(), m, PropagationRequired, func(c context.Context) error {
+		return Outbox(c).Add(OutboxEvent{Topic: "created", Payload: []byte("1")})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transactional(context.Background(), m, PropagationRequired, func(c context.Context) error {
+		if err := Outbox(c).Add(OutboxEvent{Topic: "discarded", Payload: []byte("2")}); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("err = %v, want %v", err, errFailed)
+	}
+
+	if n, err := relay.Dispatch(context.Background()); n != 1 || err != nil {
+		t.Fatalf("Dispatch() = %d, %v, want 1", n, err)
+	}
+
+	events := publisher.Events()
+	if len(events) != 1 || events[0].Topic != "created" || string(events[0].Payload) != "1" {
+		t.Fatalf("published = %+v", events)
+	}
+
+	if n, err := relay.Dispatch(context.Background()); n != 0 || err != nil {
+		t.Fatalf("Dispatch() = %d, %v, want 0", n, err)
+	}
+}
@@ -0,0 +1,135 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakePgxDB records the statements of the transactions began on it.
+type fakePgxDB struct {
+	PgxExecutor
+
+	mu  sync.Mutex
+	ops []string
+	seq int
+}
+
+func (db *fakePgxDB) record(format string, args ...any) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.ops = append(db.ops, fmt.Sprintf(format, args...))
+}
+
+func (db *fakePgxDB) BeginTx(c context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	db.mu.Lock()
+	db.seq++
+	tx := &fakePgxTx{db: db, id: db.seq}
+	db.mu.Unlock()
+
+	db.record("begin %d %s", tx.id, opts.AccessMode)
+	return tx, nil
+}
+
+type fakePgxTx struct {
+	pgx.Tx
+
+	db *fakePgxDB
+	id int
+}
+
+func (tx *fakePgxTx) Commit(context.Context) error {
+	tx.db.record("commit %d", tx.id)
+	return nil
+}
+
+func (tx *fakePgxTx) Rollback(context.Context) error {
+	tx.db.record("rollback %d", tx.id)
+	return nil
+}
+
+func (tx *fakePgxTx) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	tx.db.record("%d: %s", tx.id, sql)
+	return pgconn.CommandTag{}, nil
+}
+
+func TestPgxAdapter(t *testing.T) {
+	db := &fakePgxDB{}
+	m := TxMiddleware(NewPgxTransactionFactory(db))
+	exec := func(c context.Context, sql string) error {
+		_, err := PgxFromContext(c, db).Exec(c, sql)
+		return err
+	}
+
+	err := transactional(context.Background(), m, PropagationRequired, func(c context.Context) error {
+		if _, ok := PgxTxFromContext(c, db); !ok {
+			return errors.New("transaction is not found from the context")
+		}
+
+		if err := exec(c, "INSERT a"); err != nil {
+			return err
+		}
+
+		_ = transactional(c, m, PropagationNested, func(c context.Context) error {
+			return errFailed
+		})
+
+		if err := transactional(c, m, PropagationNotSupported, func(c context.Context) error {
+			if _, ok := PgxTxFromContext(c, db); ok {
+				return errors.New("transaction is not suspended")
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		return transactional(c, m, PropagationRequiresNew, func(c context.Context) error {
+			return exec(c, "INSERT b")
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"begin 1 ",
+		"1: INSERT a",
+		"1: SAVEPOINT gen_go_proxy_sp_2",
+		"1: ROLLBACK TO SAVEPOINT gen_go_proxy_sp_2",
+		"begin 2 ",
+		"2: INSERT b",
+		"commit 2",
+		"commit 1",
+	}
+	if !reflect.DeepEqual(db.ops, want) {
+		t.Fatalf("ops = %q, want %q", db.ops, want)
+	}
+}
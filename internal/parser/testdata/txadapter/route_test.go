@@ -0,0 +1,100 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestSQLFromContextRoute(t *testing.T) {
+	// count counts the values of the db routed by SQLFromContext in the read-only method.
+	count := func(t *testing.T, c context.Context, router DataSourceRouter, db *sql.DB) int {
+		t.Helper()
+
+		n := 0
+		err := ReadOnlyMiddleware(router)(func(c context.Context) error {
+			return SQLFromContext(c, db).QueryRowContext(c, countValues).Scan(&n)
+		})(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	open := func(t *testing.T) (*sql.DB, DataSourceRouter, func(func(c context.Context) error) func(context.Context) error) {
+		primary, replica := openSQL(t), openSQL(t)
+		router := NewReplicaRouter().Add(primary, replica)
+		return primary, router, TxMiddlewareWithOptions(NewSQLTransactionFactory(primary), WithDataSourceRouter(router))
+	}
+
+	insert := func(db *sql.DB) func(c context.Context) error {
+		return func(c context.Context) error {
+			_, err := SQLFromContext(c, db).ExecContext(c, insertValue, "a")
+			return err
+		}
+	}
+
+	t.Run("route of the request reads the write", func(t *testing.T) {
+		primary, router, m := open(t)
+		c := WithDataSourceRoute(context.Background(), router)
+		if err := transactional(c, m, PropagationRequired, insert(primary)); err != nil {
+			t.Fatal(err)
+		}
+
+		if n := count(t, c, router, primary); n != 1 {
+			t.Fatalf("count = %d, want 1 of the primary", n)
+		}
+	})
+
+	t.Run("route of the outermost method is discarded", func(t *testing.T) {
+		primary, router, m := open(t)
+		c := context.Background()
+		if err := transactional(c, m, PropagationRequired, insert(primary)); err != nil {
+			t.Fatal(err)
+		}
+
+		if n := count(t, c, router, primary); n != 0 {
+			t.Fatalf("count = %d, want 0 of the replica", n)
+		}
+	})
+
+	t.Run("write out of the transaction", func(t *testing.T) {
+		primary, router, _ := open(t)
+		c := WithDataSourceRoute(context.Background(), router)
+		if err := insert(primary)(c); err != nil {
+			t.Fatal(err)
+		}
+
+		// the write out of the transaction is not detected
+		if n := count(t, c, router, primary); n != 0 {
+			t.Fatalf("count = %d, want 0 of the replica", n)
+		}
+
+		StickToPrimary(c)
+		if n := count(t, c, router, primary); n != 1 {
+			t.Fatalf("count = %d, want 1 of the primary", n)
+		}
+	})
+}
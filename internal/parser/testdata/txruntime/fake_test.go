@@ -0,0 +1,179 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+// txRecorder records the operations of the fake transactions.
+type txRecorder struct {
+	mu          sync.Mutex
+	ops         []string
+	seq         int
+	opts        []TxOptions
+	commitErr   error
+	rollbackErr error
+}
+
+func (r *txRecorder) record(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops = append(r.ops, fmt.Sprintf(format, args...))
+}
+
+func (r *txRecorder) Ops() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.ops)
+}
+
+// Options returns the options of the transactions in the order of begin.
+func (r *txRecorder) Options() []TxOptions {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.opts)
+}
+
+// factory returns the factory of the fake transaction of the default manager.
+// the transaction supports the savepoint if savepoint is true.
+func (r *txRecorder) factory(savepoint bool) TransactionFactory {
+	return r.factoryOf(DefaultTransactionManager, savepoint)
+}
+
+// factoryOf returns the factory of the fake transaction bound to the context by manager.
+func (r *txRecorder) factoryOf(manager string, savepoint bool) TransactionFactory {
+	return func() (Transaction, error) {
+		tx := &fakeTx{recorder: r, key: fakeTxKey{manager: manager}}
+		if savepoint {
+			return &fakeSavepointTx{fakeTx: tx}, nil
+		}
+		return tx, nil
+	}
+}
+
+type fakeTxKey struct {
+	manager string
+}
+
+type fakeTx struct {
+	recorder *txRecorder
+	key      fakeTxKey
+	id       int
+}
+
+func (t *fakeTx) Begin(c context.Context, opts TxOptions) error {
+	t.recorder.mu.Lock()
+	t.recorder.seq++
+	t.id = t.recorder.seq
+	t.recorder.opts = append(t.recorder.opts, opts)
+	t.recorder.mu.Unlock()
+
+	t.recorder.record("begin %d", t.id)
+	return nil
+}
+
+func (t *fakeTx) Commit() error {
+	if t.recorder.commitErr != nil {
+		t.recorder.record("commit %d failed", t.id)
+		return t.recorder.commitErr
+	}
+
+	t.recorder.record("commit %d", t.id)
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	if t.recorder.rollbackErr != nil {
+		t.recorder.record("rollback %d failed", t.id)
+		return t.recorder.rollbackErr
+	}
+
+	t.recorder.record("rollback %d", t.id)
+	return nil
+}
+
+func (t *fakeTx) Regist(c context.Context) context.Context {
+	return context.WithValue(c, t.key, t)
+}
+
+func (t *fakeTx) From(c context.Context) error {
+	tx, ok := c.Value(t.key).(*fakeTx)
+	if !ok || tx == nil {
+		return ErrNoTransaction
+	}
+
+	t.id = tx.id
+	return nil
+}
+
+func (t *fakeTx) Suspend(c context.Context) context.Context {
+	t.recorder.record("suspend %d", t.id)
+	return context.WithValue(c, t.key, (*fakeTx)(nil))
+}
+
+type fakeSavepointTx struct {
+	*fakeTx
+}
+
+func (t *fakeSavepointTx) Savepoint(name string) error {
+	t.recorder.record("savepoint %s", name)
+	return nil
+}
+
+func (t *fakeSavepointTx) RollbackTo(name string) error {
+	t.recorder.record("rollback to %s", name)
+	return nil
+}
+
+func (t *fakeSavepointTx) Release(name string) error {
+	t.recorder.record("release %s", name)
+	return nil
+}
+
+type middleware = func(func(c context.Context) error) func(context.Context) error
+
+// transactional runs f by the middleware as the method annotated with @transactional(args...).
+// args are "key=value" pairs.
+func transactional(c context.Context, m middleware, f func(c context.Context) error, args ...string) error {
+	arguments := []invocation.Argument{}
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, "=")
+		arguments = append(arguments, invocation.Argument{Key: key, Value: value})
+	}
+
+	inv := &invocation.Invocation{
+		Interface: "Foo",
+		Method:    "Create",
+		Annotations: []invocation.Annotation{
+			{Name: "transactional", Arguments: arguments},
+		},
+	}
+	return m(f)(invocation.WithContext(c, inv))
+}
@@ -0,0 +1,236 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// listenerEvent is the event received by the listener.
+type listenerEvent struct {
+	kind  string
+	depth int
+	event TxEvent
+}
+
+// recordingListener records the events.
+type recordingListener struct {
+	mu     sync.Mutex
+	events []listenerEvent
+}
+
+func (l *recordingListener) record(kind string, event TxEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, listenerEvent{kind: kind, depth: event.Depth, event: event})
+}
+
+func (l *recordingListener) OnBegin(_ context.Context, event TxEvent)    { l.record("begin", event) }
+func (l *recordingListener) OnCommit(_ context.Context, event TxEvent)   { l.record("commit", event) }
+func (l *recordingListener) OnRollback(_ context.Context, event TxEvent) { l.record("rollback", event) }
+func (l *recordingListener) OnJoin(_ context.Context, event TxEvent)     { l.record("join", event) }
+
+// kinds returns the kinds and the depths of the events. e.g. commit 1
+func (l *recordingListener) kinds() []string {
+	kinds := []string{}
+	for _, e := range l.events {
+		kinds = append(kinds, fmt.Sprintf("%s %d", e.kind, e.depth))
+	}
+	return kinds
+}
+
+// last returns the last event.
+func (l *recordingListener) last() TxEvent {
+	return l.events[len(l.events)-1].event
+}
+
+func TestTxListener(t *testing.T) {
+	tests := []struct {
+		name        string
+		commitErr   error
+		rollbackErr error
+		run         func(c context.Context, m middleware) error
+		kinds       []string
+		err         error
+		cause       error
+	}{
+		{
+			name: "commit",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return nil })
+			},
+			kinds: []string{"begin 1", "commit 1"},
+		},
+		{
+			name: "committed with no rollback error",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return errNotFound }, "noRollbackFor=ErrNotFound")
+			},
+			kinds: []string{"begin 1", "commit 1"},
+		},
+		{
+			name:      "commit failure",
+			commitErr: errConflict,
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return nil })
+			},
+			kinds: []string{"begin 1", "commit 1"},
+			err:   errConflict,
+		},
+		{
+			name: "rollback",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return errConflict })
+			},
+			kinds: []string{"begin 1", "rollback 1"},
+			cause: errConflict,
+		},
+		{
+			name:        "rollback failure",
+			rollbackErr: errNotFound,
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return errConflict })
+			},
+			kinds: []string{"begin 1", "rollback 1"},
+			err:   errNotFound,
+			cause: errConflict,
+		},
+		{
+			name: "rollback by wrapped commit error of other transaction",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					return fmt.Errorf("create: %w", &TxError{Op: TxOpCommit, Cause: errConflict})
+				})
+			},
+			kinds: []string{"begin 1", "rollback 1"},
+			cause: errConflict,
+		},
+		{
+			name: "join",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					return transactional(c, m, func(c context.Context) error { return nil })
+				})
+			},
+			kinds: []string{"begin 1", "join 2", "commit 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &txRecorder{commitErr: tt.commitErr, rollbackErr: tt.rollbackErr}
+			listener := &recordingListener{}
+			m := TxMiddlewareWithOptions(recorder.factory(false),
+				SentinelErrors(map[string]error{"ErrNotFound": errNotFound}),
+				WithTxListener(listener),
+			)
+
+			_ = tt.run(context.Background(), m)
+			if got := listener.kinds(); !reflect.DeepEqual(got, tt.kinds) {
+				t.Fatalf("events = %v, want %v", got, tt.kinds)
+			}
+
+			event := listener.last()
+			if event.Method != "Foo.Create" {
+				t.Errorf("method = %q, want Foo.Create", event.Method)
+			}
+
+			if (tt.err == nil) != (event.Err == nil) || !errors.Is(event.Err, tt.err) {
+				t.Errorf("err = %v, want %v", event.Err, tt.err)
+			}
+
+			if (tt.cause == nil) != (event.Cause == nil) || !errors.Is(event.Cause, tt.cause) {
+				t.Errorf("cause = %v, want %v", event.Cause, tt.cause)
+			}
+		})
+	}
+}
+
+func TestExpvarTxListener(t *testing.T) {
+	listener := NewExpvarTxListener("txtest")
+	recorder := &txRecorder{}
+	m := TxMiddlewareWithOptions(recorder.factory(false), WithTxListener(listener))
+
+	_ = transactional(context.Background(), m, func(c context.Context) error {
+		return transactional(c, m, func(c context.Context) error { return nil })
+	})
+	_ = transactional(context.Background(), m, func(c context.Context) error { return errConflict })
+
+	recorder.rollbackErr = errNotFound
+	_ = transactional(context.Background(), m, func(c context.Context) error { return errConflict })
+
+	want := map[string]string{
+		"begin":          "3",
+		"commit":         "1",
+		"rollback":       "1",
+		"rollback_error": "1",
+		"join":           "1",
+		"depth_2":        "1",
+	}
+	for key, value := range want {
+		v := listener.Vars().Get(key)
+		if v == nil || v.String() != value {
+			t.Errorf("%s = %v, want %s", key, v, value)
+		}
+	}
+
+	if v := listener.Vars().Get("commit_error"); v != nil {
+		t.Errorf("commit_error = %v, want nil", v)
+	}
+
+	if expvar.Get("txtest") != listener.Vars() {
+		t.Fatal("counters are not published")
+	}
+}
+
+func TestExpvarTxListenerReuse(t *testing.T) {
+	var wg sync.WaitGroup
+	listeners := make([]*ExpvarTxListener, 10)
+	for i := range listeners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			listeners[i] = NewExpvarTxListener("txtest_shared")
+		}()
+	}
+	wg.Wait()
+
+	for _, listener := range listeners {
+		if listener.Vars() != listeners[0].Vars() {
+			t.Fatal("listeners of the same name do not share the counters")
+		}
+	}
+
+	expvar.Publish("txtest_int", new(expvar.Int))
+	listener := NewExpvarTxListener("txtest_int")
+	listener.OnBegin(context.Background(), TxEvent{})
+	if v := listener.Vars().Get("begin"); v == nil || v.String() != "1" {
+		t.Fatalf("begin = %v, want 1", v)
+	}
+}
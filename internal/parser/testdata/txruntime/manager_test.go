@@ -0,0 +1,229 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const ledgerManager = "ledger"
+
+func TestTxMiddlewareManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(c context.Context, m middleware) error
+		ops     []string
+		wantErr error
+	}{
+		{
+			name: "default manager",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return nil })
+			},
+			ops: []string{"begin 1", "commit 1"},
+		},
+		{
+			name: "unknown manager",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return nil }, "manager=unknown")
+			},
+			wantErr: ErrUnknownTransactionManager,
+		},
+		{
+			name: "first manager wraps the others",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return nil }, "manager=default|ledger")
+			},
+			ops: []string{"begin 1", "begin 2", "commit 2", "commit 1"},
+		},
+		{
+			name: "all managers roll back",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return errConflict }, "manager=default|ledger")
+			},
+			ops:     []string{"begin 1", "begin 2", "rollback 2", "rollback 1"},
+			wantErr: errConflict,
+		},
+		{
+			name: "transaction of the other manager is not joined",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					return transactional(c, m, func(c context.Context) error { return nil }, "manager=ledger")
+				})
+			},
+			ops: []string{"begin 1", "begin 2", "commit 2", "commit 1"},
+		},
+		{
+			name: "mandatory without transaction of the manager",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					return transactional(c, m, func(c context.Context) error { return nil }, "manager=ledger", "propagation=MANDATORY")
+				})
+			},
+			ops:     []string{"begin 1", "rollback 1"},
+			wantErr: ErrNoExistingTransaction,
+		},
+		{
+			name: "joined through the transaction of the other manager",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					return transactional(c, m, func(c context.Context) error {
+						return transactional(c, m, func(c context.Context) error { return nil }, "manager=ledger", "propagation=MANDATORY")
+					})
+				}, "manager=ledger")
+			},
+			ops: []string{"begin 1", "begin 2", "commit 2", "commit 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &txRecorder{}
+			m := TxMiddlewareWithOptions(recorder.factory(false), TransactionManager(ledgerManager, recorder.factoryOf(ledgerManager, false)))
+
+			err := tt.run(context.Background(), m)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := recorder.Ops(); !reflect.DeepEqual(got, tt.ops) {
+				t.Fatalf("ops = %v, want %v", got, tt.ops)
+			}
+		})
+	}
+}
+
+func TestTransactionManager(t *testing.T) {
+	primary, ledger := &txRecorder{}, &txRecorder{}
+	m := TxMiddlewareWithOptions(nil,
+		TransactionManager(DefaultTransactionManager, primary.factory(false)),
+		TransactionManager(ledgerManager, ledger.factoryOf(ledgerManager, false)),
+	)
+
+	if err := transactional(context.Background(), m, func(c context.Context) error { return nil }, "manager=ledger"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := transactional(context.Background(), m, func(c context.Context) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"begin 1", "commit 1"}
+	if got := primary.Ops(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("default ops = %v, want %v", got, want)
+	}
+
+	if got := ledger.Ops(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ledger ops = %v, want %v", got, want)
+	}
+
+	err := transactional(context.Background(), TxMiddlewareWithOptions(nil), func(c context.Context) error { return nil })
+	if !errors.Is(err, ErrNilTransactionFactory) {
+		t.Fatalf("err = %v, want %v", err, ErrNilTransactionFactory)
+	}
+}
+
+func TestTxStatusOf(t *testing.T) {
+	recorder := &txRecorder{}
+	m := TxMiddlewareWithOptions(recorder.factory(false), TransactionManager(ledgerManager, recorder.factoryOf(ledgerManager, false)))
+
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		return transactional(c, m, func(c context.Context) error {
+			want := TxStatus{IsActive: true, IsNewTransaction: true, Depth: 1}
+			if got := TxStatusFromContext(c); got != want {
+				return errors.New("status of the method is not of the first manager")
+			}
+
+			want = TxStatus{IsActive: true, Depth: 2}
+			if got := TxStatusOf(c, DefaultTransactionManager); got != want {
+				return errors.New("status of the joined transaction is not found")
+			}
+
+			if got := TxStatusOf(c, "unknown"); got.IsActive {
+				return errors.New("status of the unknown manager is active")
+			}
+			return nil
+		}, "manager=ledger|default")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetRollbackOnlyOf(t *testing.T) {
+	recorder := &txRecorder{}
+	m := TxMiddlewareWithOptions(recorder.factory(false), TransactionManager(ledgerManager, recorder.factoryOf(ledgerManager, false)))
+
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		if err := SetRollbackOnlyOf(c, "unknown"); !errors.Is(err, ErrNoTransaction) {
+			return errors.Join(errors.New("unknown manager is marked"), err)
+		}
+
+		// only the transaction of the ledger is rolled back
+		return SetRollbackOnlyOf(c, ledgerManager)
+	}, "manager=default|ledger")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []string{"begin 1", "begin 2", "rollback 2", "commit 1"}
+	if got := recorder.Ops(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterSynchronizationOf(t *testing.T) {
+	recorder := &txRecorder{}
+	m := TxMiddlewareWithOptions(recorder.factory(false), TransactionManager(ledgerManager, recorder.factoryOf(ledgerManager, false)))
+
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		for _, manager := range []string{DefaultTransactionManager, ledgerManager} {
+			err := RegisterSynchronizationOf(c, manager, Synchronization{
+				AfterCommit: func(context.Context) { recorder.record("%s after commit", manager) },
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := RegisterSynchronizationOf(c, "unknown", Synchronization{}); !errors.Is(err, ErrNoTransaction) {
+			return errors.Join(errors.New("synchronization of the unknown manager is registered"), err)
+		}
+		return nil
+	}, "manager=default|ledger")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []string{"begin 1", "begin 2", "commit 2", "ledger after commit", "commit 1", "default after commit"}
+	if got := recorder.Ops(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,232 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+var errPublish = errors.New("publish")
+
+// outboxTopics returns the topics of the events.
+func outboxTopics(events []OutboxEvent) []string {
+	topics := []string{}
+	for _, event := range events {
+		topics = append(topics, event.Topic)
+	}
+	return topics
+}
+
+func addEvent(topic string) func(c context.Context) error {
+	return func(c context.Context) error {
+		return Outbox(c).Add(OutboxEvent{Topic: topic})
+	}
+}
+
+func TestOutboxPublishAfterCommit(t *testing.T) {
+	recorder := &txRecorder{}
+	store := NewMemoryOutboxStore()
+	publisher := &MemoryPublisher{}
+	m := TxMiddlewareWithOptions(recorder.factory(false), WithOutbox(store))
+	relay := NewRelay(store, publisher)
+
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		if err := addEvent("created")(c); err != nil {
+			return err
+		}
+
+		// the event is not visible to the relay before the commit
+		if n, err := relay.Dispatch(c); n != 0 || err != nil {
+			return errors.Join(errors.New("uncommitted event is dispatched"), err)
+		}
+		return addEvent("updated")(c)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := relay.Dispatch(context.Background()); n != 2 || err != nil {
+		t.Fatalf("Dispatch() = %d, %v, want 2", n, err)
+	}
+
+	events := publisher.Events()
+	if got := outboxTopics(events); !slices.Equal(got, []string{"created", "updated"}) {
+		t.Fatalf("published = %v", got)
+	}
+
+	if events[0].ID == 0 || events[0].CreatedAt.IsZero() {
+		t.Fatalf("event = %+v, want the id and the creation time", events[0])
+	}
+
+	// the published events are not dispatched again
+	if n, err := relay.Dispatch(context.Background()); n != 0 || err != nil {
+		t.Fatalf("Dispatch() = %d, %v, want 0", n, err)
+	}
+}
+
+func TestOutboxNoPublishAfterRollback(t *testing.T) {
+	recorder := &txRecorder{}
+	store := NewMemoryOutboxStore()
+	publisher := &MemoryPublisher{}
+	m := TxMiddlewareWithOptions(recorder.factory(true), WithOutbox(store))
+
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		if err := addEvent("created")(c); err != nil {
+			return err
+		}
+		return errConflict
+	})
+	if !errors.Is(err, errConflict) {
+		t.Fatalf("err = %v, want %v", err, errConflict)
+	}
+
+	err = transactional(context.Background(), m, func(c context.Context) error {
+		if err := addEvent("kept")(c); err != nil {
+			return err
+		}
+
+		// the event of the rolled back savepoint is discarded
+		_ = transactional(c, m, func(c context.Context) error {
+			if err := addEvent("discarded")(c); err != nil {
+				return err
+			}
+			return errConflict
+		}, "propagation=NESTED")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewRelay(store, publisher).Dispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := outboxTopics(publisher.Events()); !slices.Equal(got, []string{"kept"}) {
+		t.Fatalf("published = %v, want [kept]", got)
+	}
+}
+
+func TestRelayRetriesFailedPublish(t *testing.T) {
+	recorder := &txRecorder{}
+	store := NewMemoryOutboxStore()
+	m := TxMiddlewareWithOptions(recorder.factory(false), WithOutbox(store))
+
+	for _, topic := range []string{"first", "second"} {
+		if err := transactional(context.Background(), m, addEvent(topic)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	failures := 1
+	publisher := &MemoryPublisher{}
+	relay := NewRelay(store, PublisherFunc(func(c context.Context, event OutboxEvent) error {
+		if event.Topic == "second" && failures > 0 {
+			failures--
+			return errPublish
+		}
+		return publisher.Publish(c, event)
+	}))
+
+	// the dispatch stops at the failed event
+	if n, err := relay.Dispatch(context.Background()); n != 1 || !errors.Is(err, errPublish) {
+		t.Fatalf("Dispatch() = %d, %v, want 1 and %v", n, err, errPublish)
+	}
+
+	// the failed event is retried by the next dispatch
+	if n, err := relay.Dispatch(context.Background()); n != 1 || err != nil {
+		t.Fatalf("Dispatch() = %d, %v, want 1", n, err)
+	}
+
+	if got := outboxTopics(publisher.Events()); !slices.Equal(got, []string{"first", "second"}) {
+		t.Fatalf("published = %v", got)
+	}
+}
+
+func TestRelayRun(t *testing.T) {
+	recorder := &txRecorder{}
+	store := NewMemoryOutboxStore()
+	m := TxMiddlewareWithOptions(recorder.factory(false), WithOutbox(store))
+	if err := transactional(context.Background(), m, addEvent("created")); err != nil {
+		t.Fatal(err)
+	}
+
+	failures := 2
+	errs := make(chan error, failures)
+	published := make(chan OutboxEvent, 1)
+	relay := NewRelay(store, PublisherFunc(func(c context.Context, event OutboxEvent) error {
+		if failures > 0 {
+			failures--
+			return errPublish
+		}
+		published <- event
+		return nil
+	}), RelayInterval(time.Millisecond), RelayErrorHandler(func(err error) { errs <- err }))
+
+	c, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- relay.Run(c) }()
+
+	select {
+	case event := <-published:
+		if event.Topic != "created" {
+			t.Fatalf("published = %v", event.Topic)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event is not published")
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() = %v, want %v", err, context.Canceled)
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("errors = %d, want 2", len(errs))
+	}
+}
+
+func TestOutboxAdd(t *testing.T) {
+	recorder := &txRecorder{}
+
+	if err := addEvent("created")(context.Background()); !errors.Is(err, ErrNoOutbox) {
+		t.Fatalf("err = %v, want %v", err, ErrNoOutbox)
+	}
+
+	m := TxMiddleware(recorder.factory(false))
+	if err := transactional(context.Background(), m, addEvent("created")); !errors.Is(err, ErrNoOutbox) {
+		t.Fatalf("err = %v, want %v", err, ErrNoOutbox)
+	}
+
+	m = TxMiddlewareWithOptions(recorder.factory(false), WithOutbox(NewMemoryOutboxStore()))
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		return transactional(c, m, addEvent("created"), "propagation=NOT_SUPPORTED")
+	})
+	if !errors.Is(err, ErrNoTransaction) {
+		t.Fatalf("err = %v, want %v", err, ErrNoTransaction)
+	}
+}
@@ -0,0 +1,188 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTxMiddlewareRetry(t *testing.T) {
+	// failures returns the method that fails with err n times before it succeeds.
+	failures := func(n int, err error) func(c context.Context) error {
+		return func(c context.Context) error {
+			if n > 0 {
+				n--
+				return err
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name    string
+		run     func(c context.Context, m middleware) error
+		ops     []string
+		wantErr error
+	}{
+		{
+			name: "not retried by default",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, failures(1, errConflict))
+			},
+			ops:     []string{"begin 1", "rollback 1"},
+			wantErr: errConflict,
+		},
+		{
+			name: "zero retry is not retried",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, failures(1, errConflict), "retry=0")
+			},
+			ops:     []string{"begin 1", "rollback 1"},
+			wantErr: errConflict,
+		},
+		{
+			name: "retried with fresh transaction",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, failures(1, errConflict), "retry=2", "backoff=1ms")
+			},
+			ops: []string{"begin 1", "rollback 1", "begin 2", "commit 2"},
+		},
+		{
+			name: "retry counts the attempts after the first",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, failures(5, errConflict), "retry=2")
+			},
+			ops:     []string{"begin 1", "rollback 1", "begin 2", "rollback 2", "begin 3", "rollback 3"},
+			wantErr: errConflict,
+		},
+		{
+			name: "not retryable error",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, failures(1, errNotFound), "retry=3")
+			},
+			ops:     []string{"begin 1", "rollback 1"},
+			wantErr: errNotFound,
+		},
+		{
+			name: "inner transaction is not retried",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					return transactional(c, m, failures(1, errConflict), "propagation=REQUIRES_NEW", "retry=3")
+				})
+			},
+			ops:     []string{"begin 1", "begin 2", "rollback 2", "rollback 1"},
+			wantErr: errConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &txRecorder{}
+			m := TxMiddlewareWithOptions(recorder.factory(false), Retryable(func(err error) bool {
+				return errors.Is(err, errConflict)
+			}))
+
+			err := tt.run(context.Background(), m)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := recorder.Ops(); !reflect.DeepEqual(got, tt.ops) {
+				t.Fatalf("ops = %v, want %v", got, tt.ops)
+			}
+		})
+	}
+}
+
+func TestTxMiddlewareRetryInOtherManager(t *testing.T) {
+	recorder := &txRecorder{}
+	m := TxMiddlewareWithOptions(recorder.factory(false),
+		TransactionManager("ledger", recorder.factoryOf("ledger", false)),
+		Retryable(func(err error) bool { return errors.Is(err, errConflict) }),
+	)
+
+	// the transaction of the other manager is the outermost of its manager
+	failed := false
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		return transactional(c, m, func(c context.Context) error {
+			if !failed {
+				failed = true
+				return errConflict
+			}
+			return nil
+		}, "manager=ledger", "retry=1")
+	})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []string{"begin 1", "begin 2", "rollback 2", "begin 3", "commit 3", "commit 1"}
+	if got := recorder.Ops(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %v, want %v", got, want)
+	}
+}
+
+func TestTxMiddlewareRetryBackoff(t *testing.T) {
+	recorder := &txRecorder{}
+	m := TxMiddlewareWithOptions(recorder.factory(false), Retryable(func(err error) bool { return true }))
+
+	// backoff is doubled on each retry, 20ms + 40ms
+	start := time.Now()
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		return errConflict
+	}, "retry=2", "backoff=20ms")
+	if !errors.Is(err, errConflict) {
+		t.Fatalf("err = %v, want %v", err, errConflict)
+	}
+
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Fatalf("elapsed = %v, want at least 60ms", elapsed)
+	}
+}
+
+func TestTxMiddlewareRetryCanceled(t *testing.T) {
+	recorder := &txRecorder{}
+	m := TxMiddlewareWithOptions(recorder.factory(false), Retryable(func(err error) bool { return true }))
+
+	c, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := transactional(c, m, func(c context.Context) error {
+		return errConflict
+	}, "retry=3", "backoff=1h")
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errConflict) {
+		t.Fatalf("err = %v, want %v and %v", err, context.DeadlineExceeded, errConflict)
+	}
+
+	if got, want := recorder.Ops(), []string{"begin 1", "rollback 1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %v, want %v", got, want)
+	}
+}
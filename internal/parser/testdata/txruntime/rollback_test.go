@@ -0,0 +1,308 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	errConflict = errors.New("conflict")
+	errNotFound = errors.New("not found")
+)
+
+func TestTxMiddlewareCommitOrRollback(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []TxOption
+		run     func(c context.Context, m middleware) error
+		ops     []string
+		wantErr []error
+	}{
+		{
+			name: "commit",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return nil })
+			},
+			ops: []string{"begin 1", "commit 1"},
+		},
+		{
+			name: "rollback on error",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return errConflict })
+			},
+			ops:     []string{"begin 1", "rollback 1"},
+			wantErr: []error{ErrRollbackTransaction, errConflict},
+		},
+		{
+			name: "rollback-only inner join",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					// the caller swallows the error of the joined method
+					_ = transactional(c, m, func(c context.Context) error { return errConflict })
+					return nil
+				})
+			},
+			ops:     []string{"begin 1", "rollback 1"},
+			wantErr: []error{ErrUnexpectedRollback},
+		},
+		{
+			name: "rollback-only marked by inner join",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					return transactional(c, m, func(c context.Context) error { return SetRollbackOnly(c) })
+				})
+			},
+			ops:     []string{"begin 1", "rollback 1"},
+			wantErr: []error{ErrUnexpectedRollback},
+		},
+		{
+			name: "rollback-only marked by outer",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return SetRollbackOnly(c) })
+			},
+			ops: []string{"begin 1", "rollback 1"},
+		},
+		{
+			name:    "matching rollbackFor",
+			options: []TxOption{RollbackOn(func(error) bool { return false })},
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return errConflict }, "rollbackFor=ErrConflict")
+			},
+			ops:     []string{"begin 1", "rollback 1"},
+			wantErr: []error{ErrRollbackTransaction, errConflict},
+		},
+		{
+			name:    "non-matching rollbackFor",
+			options: []TxOption{RollbackOn(func(error) bool { return false })},
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return errNotFound }, "rollbackFor=ErrConflict")
+			},
+			ops:     []string{"begin 1", "commit 1"},
+			wantErr: []error{errNotFound},
+		},
+		{
+			name: "matching noRollbackFor",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return errNotFound }, "noRollbackFor=ErrNotFound|ErrConflict")
+			},
+			ops:     []string{"begin 1", "commit 1"},
+			wantErr: []error{errNotFound},
+		},
+		{
+			name: "non-matching noRollbackFor",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return errConflict }, "noRollbackFor=ErrNotFound")
+			},
+			ops:     []string{"begin 1", "rollback 1"},
+			wantErr: []error{ErrRollbackTransaction, errConflict},
+		},
+		{
+			name: "noRollbackFor takes precedence over rollbackFor",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return errNotFound }, "rollbackFor=ErrNotFound", "noRollbackFor=ErrNotFound")
+			},
+			ops:     []string{"begin 1", "commit 1"},
+			wantErr: []error{errNotFound},
+		},
+		{
+			name: "unregistered sentinel error",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return nil }, "rollbackFor=ErrUnknown")
+			},
+			ops:     nil,
+			wantErr: []error{ErrUnregisteredSentinelError},
+		},
+		{
+			name: "requires new",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					return transactional(c, m, func(c context.Context) error { return nil }, "propagation=REQUIRES_NEW")
+				})
+			},
+			ops: []string{"begin 1", "begin 2", "commit 2", "commit 1"},
+		},
+		{
+			name: "failed requires new does not roll back the outer",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					_ = transactional(c, m, func(c context.Context) error { return errConflict }, "propagation=REQUIRES_NEW")
+					return nil
+				})
+			},
+			ops: []string{"begin 1", "begin 2", "rollback 2", "commit 1"},
+		},
+		{
+			name: "mandatory without transaction",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error { return nil }, "propagation=MANDATORY")
+			},
+			wantErr: []error{ErrNoExistingTransaction},
+		},
+		{
+			name: "never with transaction",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					return transactional(c, m, func(c context.Context) error { return nil }, "propagation=NEVER")
+				})
+			},
+			ops:     []string{"begin 1", "rollback 1"},
+			wantErr: []error{ErrExistingTransaction},
+		},
+		{
+			name: "not supported suspends",
+			run: func(c context.Context, m middleware) error {
+				return transactional(c, m, func(c context.Context) error {
+					return transactional(c, m, func(c context.Context) error {
+						if TxStatusFromContext(c).IsActive {
+							return errors.New("transaction is active")
+						}
+						return nil
+					}, "propagation=NOT_SUPPORTED")
+				})
+			},
+			ops: []string{"begin 1", "suspend 1", "commit 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &txRecorder{}
+			options := append([]TxOption{
+				SentinelErrors(map[string]error{"ErrConflict": errConflict, "ErrNotFound": errNotFound}),
+			}, tt.options...)
+
+			err := tt.run(context.Background(), TxMiddlewareWithOptions(recorder.factory(false), options...))
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Fatalf("err = %v, want %v", err, want)
+				}
+			}
+
+			if got := recorder.Ops(); !reflect.DeepEqual(got, tt.ops) {
+				t.Fatalf("ops = %v, want %v", got, tt.ops)
+			}
+		})
+	}
+}
+
+func TestTxMiddlewareCommitFailure(t *testing.T) {
+	commitErr := errors.New("connection lost")
+	recorder := &txRecorder{commitErr: commitErr}
+	err := transactional(context.Background(), TxMiddleware(recorder.factory(false)), func(c context.Context) error { return nil })
+
+	txErr := &TxError{}
+	if !errors.As(err, &txErr) || txErr.Op != TxOpCommit || !errors.Is(err, commitErr) {
+		t.Fatalf("err = %v, want commit TxError", err)
+	}
+}
+
+func TestTxMiddlewareOptions(t *testing.T) {
+	recorder := &txRecorder{}
+	err := transactional(context.Background(), TxMiddleware(recorder.factory(false)), func(c context.Context) error {
+		return nil
+	}, "isolation=serializable", "readOnly=true", "timeout=2s")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []TxOptions{{Isolation: sql.LevelSerializable, ReadOnly: true, Timeout: 2 * time.Second}}
+	if got := recorder.Options(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("options = %+v, want %+v", got, want)
+	}
+}
+
+func TestTxMiddlewareTimeout(t *testing.T) {
+	// database/sql has already rolled back the transaction when the deadline expires
+	recorder := &txRecorder{rollbackErr: sql.ErrTxDone}
+	err := transactional(context.Background(), TxMiddleware(recorder.factory(false)), func(c context.Context) error {
+		<-c.Done()
+		return nil
+	}, "timeout=10ms")
+
+	txErr := &TxError{}
+	if errors.As(err, &txErr) {
+		t.Fatalf("err = %v, want no TxError", err)
+	}
+
+	if !errors.Is(err, ErrRollbackTransaction) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want %v and %v", err, ErrRollbackTransaction, context.DeadlineExceeded)
+	}
+}
+
+func TestTxMiddlewareNestedSavepoint(t *testing.T) {
+	recorder := &txRecorder{}
+	m := TxMiddleware(recorder.factory(true))
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		if err := transactional(c, m, func(c context.Context) error { return nil }, "propagation=NESTED"); err != nil {
+			return err
+		}
+
+		// only the work of the failed nested call is rolled back
+		_ = transactional(c, m, func(c context.Context) error { return errConflict }, "propagation=NESTED")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []string{
+		"begin 1",
+		"savepoint gen_go_proxy_sp_2", "release gen_go_proxy_sp_2",
+		"savepoint gen_go_proxy_sp_2", "rollback to gen_go_proxy_sp_2",
+		"commit 1",
+	}
+	if got := recorder.Ops(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %v, want %v", got, want)
+	}
+}
+
+func TestTxMiddlewareRollbackOnlyJoinedSavepoint(t *testing.T) {
+	recorder := &txRecorder{}
+	m := TxMiddleware(recorder.factory(true))
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		return transactional(c, m, func(c context.Context) error { return SetRollbackOnly(c) })
+	})
+	if !errors.Is(err, ErrUnexpectedRollback) {
+		t.Fatalf("err = %v, want %v", err, ErrUnexpectedRollback)
+	}
+
+	// the mark of the joined call rolls back the whole transaction
+	want := []string{
+		"begin 1",
+		"savepoint gen_go_proxy_sp_2", "release gen_go_proxy_sp_2",
+		"rollback 1",
+	}
+	if got := recorder.Ops(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %v, want %v", got, want)
+	}
+}
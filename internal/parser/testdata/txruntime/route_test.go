@@ -0,0 +1,197 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"testing"
+)
+
+// readOnly runs f by the middleware of @readonly.
+func readOnly(c context.Context, router DataSourceRouter, f func(c context.Context) error) error {
+	return ReadOnlyMiddleware(router)(f)(c)
+}
+
+func TestReplicaOf(t *testing.T) {
+	router := NewReplicaRouter().Add("primary", "replica")
+	tests := []struct {
+		name     string
+		context  func() context.Context
+		readOnly bool
+		want     string
+	}{
+		{
+			name:     "no route",
+			context:  context.Background,
+			readOnly: true,
+			want:     "primary",
+		},
+		{
+			name:    "not read-only",
+			context: func() context.Context { return WithDataSourceRoute(context.Background(), router) },
+			want:    "primary",
+		},
+		{
+			name:     "read-only",
+			context:  func() context.Context { return WithDataSourceRoute(context.Background(), router) },
+			readOnly: true,
+			want:     "replica",
+		},
+		{
+			name: "stick to primary",
+			context: func() context.Context {
+				c := WithDataSourceRoute(context.Background(), router)
+				StickToPrimary(c)
+				return c
+			},
+			readOnly: true,
+			want:     "primary",
+		},
+		{
+			name:     "no replica",
+			context:  func() context.Context { return WithDataSourceRoute(context.Background(), NewReplicaRouter()) },
+			readOnly: true,
+			want:     "primary",
+		},
+		{
+			name: "replica of other type",
+			context: func() context.Context {
+				return WithDataSourceRoute(context.Background(), NewReplicaRouter().Add("primary", 1))
+			},
+			readOnly: true,
+			want:     "primary",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replicaOf(tt.context(), "primary", tt.readOnly); got != tt.want {
+				t.Fatalf("replicaOf() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplicaRouterRoundRobin(t *testing.T) {
+	router := NewReplicaRouter().Add("primary", "replica1", "replica2")
+	c := WithDataSourceRoute(context.Background(), router)
+	for _, want := range []string{"replica1", "replica2", "replica1"} {
+		if got := replicaOf(c, "primary", true); got != want {
+			t.Fatalf("replicaOf() = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	router := NewReplicaRouter().Add("primary", "replica")
+	routed := func(got *string) func(c context.Context) error {
+		return func(c context.Context) error {
+			*got = replicaOf(c, "primary", isReadOnlyRoute(c))
+			return nil
+		}
+	}
+
+	got := ""
+	if err := readOnly(context.Background(), router, routed(&got)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "replica" {
+		t.Fatalf("read-only call = %s, want replica", got)
+	}
+
+	// the route of the request is reused
+	c := WithDataSourceRoute(context.Background(), router)
+	StickToPrimary(c)
+	if err := readOnly(c, router, routed(&got)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "primary" {
+		t.Fatalf("read-only call after the write = %s, want primary", got)
+	}
+}
+
+func TestTxMiddlewareStickToPrimary(t *testing.T) {
+	router := NewReplicaRouter().Add("primary", "replica")
+	m := TxMiddlewareWithOptions((&txRecorder{}).factory(false), WithDataSourceRouter(router))
+
+	read := func(c context.Context) (string, error) {
+		got := ""
+		err := readOnly(c, router, func(c context.Context) error {
+			got = replicaOf(c, "primary", isReadOnlyRoute(c))
+			return nil
+		})
+		return got, err
+	}
+
+	t.Run("read in the transaction", func(t *testing.T) {
+		got := ""
+		err := transactional(context.Background(), m, func(c context.Context) error {
+			var err error
+			got, err = read(c)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != "primary" {
+			t.Fatalf("read after the write = %s, want primary", got)
+		}
+	})
+
+	t.Run("route of the request", func(t *testing.T) {
+		c := WithDataSourceRoute(context.Background(), router)
+		if err := transactional(c, m, func(c context.Context) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := read(c); err != nil || got != "primary" {
+			t.Fatalf("read after the write = %s, %v, want primary", got, err)
+		}
+	})
+
+	t.Run("route of the outermost method", func(t *testing.T) {
+		// the route is discarded with the call, so the next call reads the replica
+		c := context.Background()
+		if err := transactional(c, m, func(c context.Context) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := read(c); err != nil || got != "replica" {
+			t.Fatalf("read after the write = %s, %v, want replica", got, err)
+		}
+	})
+
+	t.Run("read-only transaction", func(t *testing.T) {
+		c := WithDataSourceRoute(context.Background(), router)
+		if err := transactional(c, m, func(c context.Context) error { return nil }, "readOnly=true"); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := read(c); err != nil || got != "replica" {
+			t.Fatalf("read after the read-only transaction = %s, %v, want replica", got, err)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package txtest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// register registers the synchronization that records its callbacks by name.
+func register(c context.Context, recorder *txRecorder, name string) error {
+	return RegisterSynchronization(c, Synchronization{
+		AfterCommit:   func(context.Context) { recorder.record("%s after commit", name) },
+		AfterRollback: func(context.Context) { recorder.record("%s after rollback", name) },
+	})
+}
+
+func TestSynchronizationOfSavepoint(t *testing.T) {
+	recorder := &txRecorder{}
+	m := TxMiddleware(recorder.factory(true))
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		if err := register(c, recorder, "outer"); err != nil {
+			return err
+		}
+
+		err := transactional(c, m, func(c context.Context) error {
+			return register(c, recorder, "released")
+		}, "propagation=NESTED")
+		if err != nil {
+			return err
+		}
+
+		_ = transactional(c, m, func(c context.Context) error {
+			if err := register(c, recorder, "discarded"); err != nil {
+				return err
+			}
+			return errConflict
+		}, "propagation=NESTED")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []string{
+		"begin 1",
+		"savepoint gen_go_proxy_sp_2", "release gen_go_proxy_sp_2",
+		"savepoint gen_go_proxy_sp_2", "rollback to gen_go_proxy_sp_2", "discarded after rollback",
+		"commit 1",
+		"outer after commit", "released after commit",
+	}
+	if got := recorder.Ops(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %v, want %v", got, want)
+	}
+}
+
+func TestSynchronizationOfRollbackOnlySavepoint(t *testing.T) {
+	recorder := &txRecorder{}
+	m := TxMiddleware(recorder.factory(true))
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		return transactional(c, m, func(c context.Context) error {
+			if err := register(c, recorder, "nested"); err != nil {
+				return err
+			}
+			return SetRollbackOnly(c)
+		}, "propagation=NESTED")
+	})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []string{
+		"begin 1",
+		"savepoint gen_go_proxy_sp_2", "rollback to gen_go_proxy_sp_2", "nested after rollback",
+		"commit 1",
+	}
+	if got := recorder.Ops(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %v, want %v", got, want)
+	}
+}
+
+func TestSynchronizationOfJoinedMethod(t *testing.T) {
+	recorder := &txRecorder{}
+	m := TxMiddleware(recorder.factory(false))
+	err := transactional(context.Background(), m, func(c context.Context) error {
+		return transactional(c, m, func(c context.Context) error {
+			return register(c, recorder, "joined")
+		})
+	})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []string{"begin 1", "commit 1", "joined after commit"}
+	if got := recorder.Ops(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %v, want %v", got, want)
+	}
+
+	if err := RegisterSynchronization(context.Background(), Synchronization{}); err != ErrNoTransaction {
+		t.Fatalf("RegisterSynchronization() = %v, want ErrNoTransaction", err)
+	}
+}
@@ -0,0 +1,120 @@
+﻿// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const txModuleName = "example.com/txtest"
+
+// runTxModule generates the transaction middleware and the adapters into the module
+// of the temporary directory with the files of testdata/{name} and runs go test on it.
+// the generated code is tested by the test files of testdata, because it is the code of the user package.
+// requires are "{module path} {version}" of the modules used by the test files.
+func runTxModule(t *testing.T, name string, adapters []string, requires ...string) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skip the tests of the generated transaction middleware in short mode")
+	}
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command is not found")
+	}
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	goMod := fmt.Sprintf("module %s\n\ngo 1.23.3\n\nrequire github.com/ISSuh/gen-go-proxy v0.0.0\n\nreplace github.com/ISSuh/gen-go-proxy => %s\n", txModuleName, root)
+	for _, require := range requires {
+		goMod += "\nrequire " + require + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join("testdata", name)
+	items, err := os.ReadDir(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, item := range items {
+		data, err := os.ReadFile(filepath.Join(src, item.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, item.Name()), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := NewGenerator()
+	tmpl := Template{Data: &TemplateData{PackageName: "txtest"}}
+	if err := g.GenerateTxMiddleware(filepath.Join(dir, "proxy_middleware_tx.go"), tmpl); err != nil {
+		t.Fatalf("GenerateTxMiddleware() = %v", err)
+	}
+
+	for _, adapter := range adapters {
+		if err := g.GenerateTxAdapter(filepath.Join(dir, fmt.Sprintf(TxAdapterFileNameFormat, adapter)), adapter, tmpl); err != nil {
+			t.Fatalf("GenerateTxAdapter(%s) = %v", adapter, err)
+		}
+	}
+
+	for _, args := range [][]string{{"mod", "tidy"}, {"vet", "./..."}, {"test", "-count=1", "./..."}} {
+		cmd := exec.Command(goBin, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("go %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
+
+func TestTxRuntime(t *testing.T) {
+	runTxModule(t, "txruntime", nil)
+}
+
+// TestTxAdapter runs the adapters of database/sql, sqlx and gorm against sqlite.
+// the pgx adapter is run against the fake connection because it supports only postgres.
+func TestTxAdapter(t *testing.T) {
+	runTxModule(t, "txadapter", TxAdapters,
+		"github.com/mattn/go-sqlite3 v1.14.22",
+		"github.com/jmoiron/sqlx v1.4.0",
+		"gorm.io/gorm v1.25.12",
+		"gorm.io/driver/sqlite v1.5.7",
+		"github.com/jackc/pgx/v5 v5.7.1",
+	)
+}
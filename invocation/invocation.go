@@ -0,0 +1,148 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package invocation provides the metadata of the proxied method call.
+// the generated proxy registers the invocation to the context before
+// running the middlewares, so the middlewares can read the annotations
+// declared on the method.
+//
+//	func middleware(next func(context.Context) error) func(context.Context) error {
+//		return func(c context.Context) error {
+//			inv, ok := invocation.FromContext(c)
+//			if ok {
+//				fmt.Println(inv.Interface, inv.Method)
+//			}
+//			return next(c)
+//		}
+//	}
+package invocation
+
+import (
+	"context"
+)
+
+type invocationKey struct{}
+
+type transactionKey struct{}
+
+// Argument is an argument of the annotation.
+// positional arguments are named by the annotation schema at generation time.
+type Argument struct {
+	Key   string
+	Value string
+}
+
+// Annotation is an annotation declared on the method.
+type Annotation struct {
+	Name      string
+	Arguments []Argument
+}
+
+// Get returns the value of the argument.
+func (a Annotation) Get(key string) (string, bool) {
+	for _, arg := range a.Arguments {
+		if arg.Key == key {
+			return arg.Value, true
+		}
+	}
+	return "", false
+}
+
+// Invocation is the metadata of the proxied method call.
+type Invocation struct {
+	// Package is the import path of the package of the proxied type.
+	// it is the package name if the import path is not resolved at generation time.
+	Package string
+
+	// Interface is the name of the proxied interface, struct or function type.
+	Interface string
+
+	// Method is the name of the proxied method.
+	Method string
+
+	// Annotations are the annotations of the method in middleware order.
+	Annotations []Annotation
+}
+
+// Annotation returns the annotation declared on the method.
+func (i *Invocation) Annotation(name string) (Annotation, bool) {
+	if i == nil {
+		return Annotation{}, false
+	}
+
+	for _, annotation := range i.Annotations {
+		if annotation.Name == name {
+			return annotation, true
+		}
+	}
+	return Annotation{}, false
+}
+
+// Argument returns the value of the argument of the annotation.
+func (i *Invocation) Argument(annotation, key string) (string, bool) {
+	a, ok := i.Annotation(annotation)
+	if !ok {
+		return "", false
+	}
+	return a.Get(key)
+}
+
+// String returns the qualified method name. e.g. Foo.Create
+func (i *Invocation) String() string {
+	if i == nil {
+		return ""
+	}
+	return i.Interface + "." + i.Method
+}
+
+// FullName returns the method name qualified by the package. e.g. example.com/service.Foo.Create
+// it distinguishes the methods of the same name in the different packages.
+func (i *Invocation) FullName() string {
+	if i == nil || i.Package == "" {
+		return i.String()
+	}
+	return i.Package + "." + i.String()
+}
+
+// WithContext returns a context with the invocation.
+func WithContext(c context.Context, i *Invocation) context.Context {
+	return context.WithValue(c, invocationKey{}, i)
+}
+
+// FromContext returns the invocation of the innermost proxied method call.
+func FromContext(c context.Context) (*Invocation, bool) {
+	i, ok := c.Value(invocationKey{}).(*Invocation)
+	return i, ok && i != nil
+}
+
+// WithTransaction returns a context that marks whether the call runs in the transaction
+// began by the transaction middleware. the other middlewares read it to avoid re-running
+// the work of the transaction, e.g. retry.
+func WithTransaction(c context.Context, active bool) context.Context {
+	return context.WithValue(c, transactionKey{}, active)
+}
+
+// InTransaction reports whether the call runs in the transaction.
+func InTransaction(c context.Context) bool {
+	active, _ := c.Value(transactionKey{}).(bool)
+	return active
+}
@@ -0,0 +1,249 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package ratelimit provides the token bucket middleware of @ratelimit.
+// the bucket is kept per proxied method, or per the key extracted from the context.
+// the idle bucket is evicted, so the buckets of the short-lived keys do not accumulate.
+//
+//	type Foo interface {
+//		// @ratelimit(rps=100, burst=20)
+//		Fetch(c context.Context, id int) (dto.Foo, error)
+//	}
+//
+//	limiter := ratelimit.New(ratelimit.KeyFromContext(tenantKey{}))
+//	m := map[string][]func(func(context.Context) error) func(context.Context) error{
+//		"ratelimit": {limiter.Middleware()},
+//	}
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+const (
+	rateLimitAnnotation = "ratelimit"
+
+	rpsArgument   = "rps"
+	burstArgument = "burst"
+
+	keySeparator = "/"
+
+	defaultIdleTimeout = 5 * time.Minute
+)
+
+var (
+	ErrRateLimited = errors.New("rate limited")
+	ErrInvalidRate = errors.New("invalid rate")
+)
+
+// Clock returns the current time. it is replaced to test deterministically.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Option configures the limiter.
+type Option func(*Limiter)
+
+// WithClock sets the clock of the limiter. default is the system clock.
+func WithClock(clock Clock) Option {
+	return func(l *Limiter) {
+		l.clock = clock
+	}
+}
+
+// WithKey sets the function that extracts the key of the bucket from the context.
+// the bucket is kept per method and key. the empty key shares the bucket of the method.
+func WithKey(f func(c context.Context) string) Option {
+	return func(l *Limiter) {
+		l.key = f
+	}
+}
+
+// WithIdleTimeout sets the time after which the unused bucket is evicted. default is 5m.
+// the bucket is evicted only after it is refilled to burst, so the eviction does not loosen the limit.
+// the non-positive timeout keeps the buckets forever.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(l *Limiter) {
+		l.idleTimeout = timeout
+	}
+}
+
+// KeyFromContext keeps the bucket per the value of the context key. e.g. tenant ID
+func KeyFromContext(key any) Option {
+	return WithKey(func(c context.Context) string {
+		value := c.Value(key)
+		if value == nil {
+			return ""
+		}
+		return fmt.Sprint(value)
+	})
+}
+
+// bucket is the token bucket.
+type bucket struct {
+	rps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// take takes a token if the bucket has it after refilling.
+func (b *bucket) take(now time.Time) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rps)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// full reports whether the bucket is refilled to burst at now.
+func (b *bucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.rps >= b.burst
+}
+
+// Limiter keeps the token buckets of the proxied methods.
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	clock   Clock
+	key     func(c context.Context) string
+
+	idleTimeout time.Duration
+	lastEvicted time.Time
+}
+
+// New returns a limiter.
+func New(opts ...Option) *Limiter {
+	l := &Limiter{
+		buckets:     map[string]*bucket{},
+		clock:       systemClock{},
+		idleTimeout: defaultIdleTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Middleware returns a middleware of @ratelimit.
+// the call is rejected with ErrRateLimited if the bucket has no token.
+func (l *Limiter) Middleware() func(func(c context.Context) error) func(context.Context) error {
+	return func(next func(c context.Context) error) func(context.Context) error {
+		return func(c context.Context) error {
+			inv, _ := invocation.FromContext(c)
+			rps, burst, err := rateFromInvocation(inv)
+			if err != nil {
+				return err
+			}
+
+			key := inv.FullName()
+			if l.key != nil {
+				if k := l.key(c); k != "" {
+					key += keySeparator + k
+				}
+			}
+
+			if !l.allow(key, rps, burst) {
+				return fmt.Errorf("%w on %s", ErrRateLimited, key)
+			}
+			return next(c)
+		}
+	}
+}
+
+func (l *Limiter) allow(key string, rps, burst float64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	l.evict(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{rps: rps, burst: burst, tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+	return b.take(now)
+}
+
+// evict removes the buckets unused for the idle timeout and refilled to burst.
+// it scans the buckets at most once per the idle timeout.
+func (l *Limiter) evict(now time.Time) {
+	if l.idleTimeout <= 0 || now.Sub(l.lastEvicted) < l.idleTimeout {
+		return
+	}
+	l.lastEvicted = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.idleTimeout && b.full(now) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateFromInvocation returns the rate and the burst declared on the method.
+// the burst is the rps rounded up if it is not declared.
+func rateFromInvocation(inv *invocation.Invocation) (float64, float64, error) {
+	value, ok := inv.Argument(rateLimitAnnotation, rpsArgument)
+	if !ok || value == "" {
+		return 0, 0, fmt.Errorf("%w. rps is not declared on %s", ErrInvalidRate, inv)
+	}
+
+	rps, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if rps <= 0 {
+		return 0, 0, fmt.Errorf("%w. rps must be positive on %s", ErrInvalidRate, inv)
+	}
+
+	burst := math.Max(1, math.Ceil(rps))
+	if value, ok := inv.Argument(rateLimitAnnotation, burstArgument); ok && value != "" {
+		b, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, 0, err
+		}
+		burst = math.Max(1, float64(b))
+	}
+	return rps, burst, nil
+}
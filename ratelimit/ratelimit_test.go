@@ -0,0 +1,189 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+// fakeClock is the clock advanced by the test.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type tenantKey struct{}
+
+// fetch returns the context of the call of Foo.Fetch annotated with @ratelimit(args...).
+// args are "key=value" pairs.
+func fetch(c context.Context, args ...string) context.Context {
+	arguments := []invocation.Argument{}
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, "=")
+		arguments = append(arguments, invocation.Argument{Key: key, Value: value})
+	}
+
+	return invocation.WithContext(c, &invocation.Invocation{
+		Package:   "example.com/service",
+		Interface: "Foo",
+		Method:    "Fetch",
+		Annotations: []invocation.Annotation{
+			{Name: rateLimitAnnotation, Arguments: arguments},
+		},
+	})
+}
+
+// allowed returns the number of the calls allowed in n calls.
+func allowed(l *Limiter, c context.Context, n int) int {
+	count := 0
+	for range n {
+		err := l.Middleware()(func(context.Context) error { return nil })(c)
+		if err == nil {
+			count++
+		}
+	}
+	return count
+}
+
+func TestLimiterRefill(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := New(WithClock(clock))
+	c := fetch(context.Background(), "rps=10", "burst=5")
+
+	if n := allowed(l, c, 10); n != 5 {
+		t.Fatalf("allowed = %d, want burst 5", n)
+	}
+
+	err := l.Middleware()(func(context.Context) error { return nil })(c)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("err = %v, want %v", err, ErrRateLimited)
+	}
+
+	// 10 rps refills a token per 100ms
+	clock.Advance(100 * time.Millisecond)
+	if n := allowed(l, c, 10); n != 1 {
+		t.Fatalf("allowed = %d, want 1 after 100ms", n)
+	}
+
+	clock.Advance(250 * time.Millisecond)
+	if n := allowed(l, c, 10); n != 2 {
+		t.Fatalf("allowed = %d, want 2 after 250ms", n)
+	}
+
+	// the bucket is refilled up to burst
+	clock.Advance(time.Hour)
+	if n := allowed(l, c, 10); n != 5 {
+		t.Fatalf("allowed = %d, want burst 5 after an hour", n)
+	}
+}
+
+func TestLimiterDefaultBurst(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := New(WithClock(clock))
+
+	if n := allowed(l, fetch(context.Background(), "rps=2.5"), 10); n != 3 {
+		t.Fatalf("allowed = %d, want rps rounded up 3", n)
+	}
+}
+
+func TestLimiterKey(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := New(WithClock(clock), KeyFromContext(tenantKey{}))
+
+	a := fetch(context.WithValue(context.Background(), tenantKey{}, "a"), "rps=1")
+	b := fetch(context.WithValue(context.Background(), tenantKey{}, "b"), "rps=1")
+	if n := allowed(l, a, 2) + allowed(l, b, 2); n != 2 {
+		t.Fatalf("allowed = %d, want 1 per tenant", n)
+	}
+
+	if _, ok := l.buckets["example.com/service.Foo.Fetch/a"]; !ok {
+		t.Fatalf("buckets = %v, want the bucket of tenant a", l.buckets)
+	}
+}
+
+func TestLimiterInvalidRate(t *testing.T) {
+	l := New()
+	for _, args := range [][]string{nil, {"rps=0"}, {"rps=-1"}} {
+		err := l.Middleware()(func(context.Context) error { return nil })(fetch(context.Background(), args...))
+		if !errors.Is(err, ErrInvalidRate) {
+			t.Errorf("err of %v = %v, want %v", args, err, ErrInvalidRate)
+		}
+	}
+}
+
+func TestLimiterIdleEviction(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := New(WithClock(clock), WithIdleTimeout(time.Minute), KeyFromContext(tenantKey{}))
+
+	fast := fetch(context.WithValue(context.Background(), tenantKey{}, "fast"), "rps=10")
+	slow := fetch(context.WithValue(context.Background(), tenantKey{}, "slow"), "rps=0.001", "burst=1")
+	allowed(l, fast, 1)
+	allowed(l, slow, 1)
+
+	clock.Advance(time.Minute)
+	allowed(l, fetch(context.Background(), "rps=10"), 1)
+
+	// the slow bucket is not refilled yet, so evicting it would loosen the limit
+	if _, ok := l.buckets["example.com/service.Foo.Fetch/fast"]; ok {
+		t.Fatal("idle bucket is not evicted")
+	}
+
+	if n := allowed(l, slow, 1); n != 0 {
+		t.Fatal("the bucket not refilled is evicted")
+	}
+
+	if len(l.buckets) != 2 {
+		t.Fatalf("buckets = %d, want 2", len(l.buckets))
+	}
+}
+
+func TestLimiterNoEviction(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := New(WithClock(clock), WithIdleTimeout(0))
+	allowed(l, fetch(context.Background(), "rps=10"), 1)
+
+	clock.Advance(24 * time.Hour)
+	allowed(l, fetch(context.Background(), "rps=10"), 1)
+	if len(l.buckets) != 1 {
+		t.Fatalf("buckets = %d, want 1", len(l.buckets))
+	}
+}
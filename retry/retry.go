@@ -0,0 +1,302 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package retry provides the middleware of @retry.
+// the arguments of the annotation are read from the invocation of the proxied method.
+//
+//	type Foo interface {
+//		// @retry(max=5, backoff=exponential, initial=100ms, jitter=true, on=ErrTemporary)
+//		Fetch(c context.Context, id int) (dto.Foo, error)
+//	}
+//
+//	m := map[string][]func(func(context.Context) error) func(context.Context) error{
+//		"retry": {retry.Middleware(retry.SentinelErrors(map[string]error{
+//			"ErrTemporary": client.ErrTemporary,
+//		}))},
+//	}
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+const (
+	retryAnnotation = "retry"
+
+	maxArgument      = "max"
+	backoffArgument  = "backoff"
+	initialArgument  = "initial"
+	maxDelayArgument = "maxDelay"
+	jitterArgument   = "jitter"
+	onArgument       = "on"
+
+	argumentValueSeparator = "|"
+
+	defaultMaxAttempts = 3
+	defaultInitial     = 100 * time.Millisecond
+
+	// maxBackoffShift bounds the exponential backoff to avoid the overflow.
+	maxBackoffShift = 30
+)
+
+var (
+	ErrUnregisteredSentinelError = errors.New("unregistered sentinel error")
+	ErrUnknownBackoff            = errors.New("unknown backoff")
+)
+
+// Backoff is the strategy of the delay between the attempts.
+type Backoff string
+
+const (
+	// BackoffConstant waits initial between the attempts.
+	BackoffConstant Backoff = "constant"
+
+	// BackoffLinear waits initial multiplied by the number of the failed attempts.
+	BackoffLinear Backoff = "linear"
+
+	// BackoffExponential waits initial doubled on each failed attempt.
+	BackoffExponential Backoff = "exponential"
+)
+
+// Attempt is the failed attempt that is retried.
+type Attempt struct {
+	// Invocation is the invocation of the retried method.
+	Invocation *invocation.Invocation
+
+	// Attempt is the number of the failed attempt from 1.
+	Attempt int
+
+	// Delay is the delay before the next attempt.
+	Delay time.Duration
+
+	// Err is the error of the failed attempt.
+	Err error
+}
+
+// Option configures the retry middleware.
+type Option func(*config)
+
+type config struct {
+	sentinelErrors map[string]error
+	retryable      func(err error) bool
+	onRetry        func(c context.Context, attempt Attempt)
+}
+
+// SentinelErrors registers the sentinel errors by name.
+// the name is referenced by on of the annotation.
+func SentinelErrors(errs map[string]error) Option {
+	return func(c *config) {
+		for name, err := range errs {
+			c.sentinelErrors[name] = err
+		}
+	}
+}
+
+// Retryable decides whether the error is retried if on is not declared on the method.
+// by default, every error is retried.
+func Retryable(f func(err error) bool) Option {
+	return func(c *config) {
+		c.retryable = f
+	}
+}
+
+// OnRetry sets the function called before each retry.
+// the invocation of the attempt is used for the labels of the metrics and logs.
+//
+//	retry.OnRetry(func(c context.Context, attempt retry.Attempt) {
+//		retries.WithLabelValues(attempt.Invocation.Interface, attempt.Invocation.Method).Inc()
+//	})
+func OnRetry(f func(c context.Context, attempt Attempt)) Option {
+	return func(c *config) {
+		c.onRetry = f
+	}
+}
+
+// policy is the retry declared on the proxied method.
+type policy struct {
+	maxAttempts int
+	backoff     Backoff
+	initial     time.Duration
+	maxDelay    time.Duration
+	jitter      bool
+	on          []error
+}
+
+func policyFromInvocation(inv *invocation.Invocation, config config) (policy, error) {
+	p := policy{
+		maxAttempts: defaultMaxAttempts,
+		backoff:     BackoffExponential,
+		initial:     defaultInitial,
+	}
+
+	var err error
+	if value, ok := inv.Argument(retryAnnotation, maxArgument); ok && value != "" {
+		if p.maxAttempts, err = strconv.Atoi(value); err != nil {
+			return policy{}, err
+		}
+	}
+
+	if value, ok := inv.Argument(retryAnnotation, backoffArgument); ok && value != "" {
+		p.backoff = Backoff(value)
+		switch p.backoff {
+		case BackoffConstant, BackoffLinear, BackoffExponential:
+		default:
+			return policy{}, fmt.Errorf("%w %s on %s", ErrUnknownBackoff, value, inv)
+		}
+	}
+
+	if value, ok := inv.Argument(retryAnnotation, initialArgument); ok && value != "" {
+		if p.initial, err = time.ParseDuration(value); err != nil {
+			return policy{}, err
+		}
+	}
+
+	if value, ok := inv.Argument(retryAnnotation, maxDelayArgument); ok && value != "" {
+		if p.maxDelay, err = time.ParseDuration(value); err != nil {
+			return policy{}, err
+		}
+	}
+
+	if value, ok := inv.Argument(retryAnnotation, jitterArgument); ok && value != "" {
+		if p.jitter, err = strconv.ParseBool(value); err != nil {
+			return policy{}, err
+		}
+	}
+
+	if value, ok := inv.Argument(retryAnnotation, onArgument); ok && value != "" {
+		for _, name := range strings.Split(value, argumentValueSeparator) {
+			sentinel, ok := config.sentinelErrors[strings.TrimSpace(name)]
+			if !ok {
+				return policy{}, fmt.Errorf("%w %s on %s", ErrUnregisteredSentinelError, name, inv)
+			}
+			p.on = append(p.on, sentinel)
+		}
+	}
+	return p, nil
+}
+
+func (p policy) shouldRetry(err error, config config) bool {
+	if len(p.on) != 0 {
+		for _, sentinel := range p.on {
+			if errors.Is(err, sentinel) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if config.retryable != nil {
+		return config.retryable(err)
+	}
+	return true
+}
+
+// delay returns the delay after the failed attempt.
+// the delay is clamped to maxDelay, or to the max duration if maxDelay is not declared.
+func (p policy) delay(attempt int) time.Duration {
+	limit := p.maxDelay
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+
+	d := p.initial
+	switch p.backoff {
+	case BackoffLinear:
+		if d > limit/time.Duration(attempt) {
+			d = limit
+		} else {
+			d *= time.Duration(attempt)
+		}
+	case BackoffExponential:
+		shift := min(attempt-1, maxBackoffShift)
+		if d > limit>>shift {
+			d = limit
+		} else {
+			d <<= shift
+		}
+	}
+	d = min(d, limit)
+
+	// equal jitter. the delay is between half and full
+	if p.jitter && d > 1 {
+		d = d/2 + rand.N(d/2)
+	}
+	return d
+}
+
+// Middleware returns a middleware of @retry.
+// the method is re-run while it fails with the retryable error up to max attempts.
+// the method is not retried in the active transaction, because the work of the
+// transaction can not be re-run by the method. declare @retry before @transactional
+// to retry the whole transaction.
+func Middleware(opts ...Option) func(func(c context.Context) error) func(context.Context) error {
+	config := config{
+		sentinelErrors: map[string]error{},
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return func(next func(c context.Context) error) func(context.Context) error {
+		return func(c context.Context) error {
+			inv, _ := invocation.FromContext(c)
+			p, err := policyFromInvocation(inv, config)
+			if err != nil {
+				return err
+			}
+
+			if invocation.InTransaction(c) {
+				return next(c)
+			}
+
+			for attempt := 1; ; attempt++ {
+				err := next(c)
+				if err == nil || attempt >= p.maxAttempts || !p.shouldRetry(err, config) {
+					return err
+				}
+
+				delay := p.delay(attempt)
+				if config.onRetry != nil {
+					config.onRetry(c, Attempt{Invocation: inv, Attempt: attempt, Delay: delay, Err: err})
+				}
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-c.Done():
+					timer.Stop()
+					return errors.Join(c.Err(), err)
+				case <-timer.C:
+				}
+			}
+		}
+	}
+}
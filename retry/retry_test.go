@@ -0,0 +1,287 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+var (
+	errTemporary = errors.New("temporary")
+	errPermanent = errors.New("permanent")
+)
+
+// retried runs f by the middleware as the method annotated with @retry(args...),
+// and returns the number of the attempts. args are "key=value" pairs.
+func retried(t *testing.T, c context.Context, m func(func(context.Context) error) func(context.Context) error, f func(attempt int) error, args ...string) (int, error) {
+	t.Helper()
+
+	arguments := []invocation.Argument{}
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, "=")
+		arguments = append(arguments, invocation.Argument{Key: key, Value: value})
+	}
+
+	inv := &invocation.Invocation{
+		Interface: "Foo",
+		Method:    "Fetch",
+		Annotations: []invocation.Annotation{
+			{Name: retryAnnotation, Arguments: arguments},
+		},
+	}
+
+	attempts := 0
+	err := m(func(c context.Context) error {
+		attempts++
+		return f(attempts)
+	})(invocation.WithContext(c, inv))
+	return attempts, err
+}
+
+func TestMiddleware(t *testing.T) {
+	m := Middleware(SentinelErrors(map[string]error{
+		"ErrTemporary": errTemporary,
+	}))
+
+	tests := []struct {
+		name     string
+		args     []string
+		f        func(attempt int) error
+		attempts int
+		wantErr  error
+	}{
+		{
+			name:     "succeeds",
+			f:        func(int) error { return nil },
+			attempts: 1,
+		},
+		{
+			name: "retried until success",
+			args: []string{"initial=1ms"},
+			f: func(attempt int) error {
+				if attempt < 3 {
+					return errTemporary
+				}
+				return nil
+			},
+			attempts: 3,
+		},
+		{
+			name:     "max attempts include the first",
+			args:     []string{"max=2", "initial=1ms"},
+			f:        func(int) error { return errTemporary },
+			attempts: 2,
+			wantErr:  errTemporary,
+		},
+		{
+			name:     "default max attempts",
+			args:     []string{"initial=1ms"},
+			f:        func(int) error { return errTemporary },
+			attempts: defaultMaxAttempts,
+			wantErr:  errTemporary,
+		},
+		{
+			name:     "on retries the declared error",
+			args:     []string{"initial=1ms", "on=ErrTemporary"},
+			f:        func(int) error { return errors.Join(errors.New("fetch"), errTemporary) },
+			attempts: defaultMaxAttempts,
+			wantErr:  errTemporary,
+		},
+		{
+			name:     "on does not retry the other error",
+			args:     []string{"initial=1ms", "on=ErrTemporary"},
+			f:        func(int) error { return errPermanent },
+			attempts: 1,
+			wantErr:  errPermanent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts, err := retried(t, context.Background(), m, tt.f, tt.args...)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if attempts != tt.attempts {
+				t.Fatalf("attempts = %d, want %d", attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestMiddlewareRetryable(t *testing.T) {
+	m := Middleware(Retryable(func(err error) bool { return errors.Is(err, errTemporary) }))
+
+	attempts, err := retried(t, context.Background(), m, func(int) error { return errPermanent }, "initial=1ms")
+	if !errors.Is(err, errPermanent) || attempts != 1 {
+		t.Fatalf("attempts = %d, err = %v, want 1 and %v", attempts, err, errPermanent)
+	}
+}
+
+func TestMiddlewareInvalidArgument(t *testing.T) {
+	m := Middleware()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{name: "unregistered sentinel error", args: []string{"on=ErrTemporary"}, wantErr: ErrUnregisteredSentinelError},
+		{name: "unknown backoff", args: []string{"backoff=fibonacci"}, wantErr: ErrUnknownBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts, err := retried(t, context.Background(), m, func(int) error { return nil }, tt.args...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if attempts != 0 {
+				t.Fatalf("attempts = %d, want 0", attempts)
+			}
+		})
+	}
+}
+
+func TestMiddlewareInTransaction(t *testing.T) {
+	c := invocation.WithTransaction(context.Background(), true)
+	attempts, err := retried(t, c, Middleware(), func(int) error { return errTemporary }, "initial=1ms")
+	if !errors.Is(err, errTemporary) || attempts != 1 {
+		t.Fatalf("attempts = %d, err = %v, want 1 and %v", attempts, err, errTemporary)
+	}
+}
+
+func TestMiddlewareCanceled(t *testing.T) {
+	c, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	attempts, err := retried(t, c, Middleware(), func(int) error { return errTemporary }, "initial=1h")
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errTemporary) {
+		t.Fatalf("err = %v, want %v and %v", err, context.DeadlineExceeded, errTemporary)
+	}
+
+	if attempts != 1 {
+		t.Fatalf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestMiddlewareOnRetry(t *testing.T) {
+	var got []Attempt
+	m := Middleware(OnRetry(func(c context.Context, attempt Attempt) {
+		got = append(got, attempt)
+	}))
+
+	_, err := retried(t, context.Background(), m, func(int) error { return errTemporary }, "backoff=constant", "initial=1ms")
+	if !errors.Is(err, errTemporary) {
+		t.Fatalf("err = %v, want %v", err, errTemporary)
+	}
+
+	if len(got) != defaultMaxAttempts-1 {
+		t.Fatalf("retries = %d, want %d", len(got), defaultMaxAttempts-1)
+	}
+
+	for i, attempt := range got {
+		if attempt.Attempt != i+1 || attempt.Delay != time.Millisecond || attempt.Invocation.Method != "Fetch" {
+			t.Errorf("attempt %d = %+v", i, attempt)
+		}
+	}
+}
+
+func TestPolicyDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy policy
+		want   []time.Duration
+	}{
+		{
+			name:   "constant",
+			policy: policy{backoff: BackoffConstant, initial: 100 * time.Millisecond},
+			want:   []time.Duration{100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond},
+		},
+		{
+			name:   "linear",
+			policy: policy{backoff: BackoffLinear, initial: 100 * time.Millisecond},
+			want:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond},
+		},
+		{
+			name:   "exponential",
+			policy: policy{backoff: BackoffExponential, initial: 100 * time.Millisecond},
+			want:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond},
+		},
+		{
+			name:   "max delay",
+			policy: policy{backoff: BackoffExponential, initial: 100 * time.Millisecond, maxDelay: 250 * time.Millisecond},
+			want:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				if got := tt.policy.delay(i + 1); got != want {
+					t.Errorf("delay(%d) = %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("overflow", func(t *testing.T) {
+		p := policy{backoff: BackoffExponential, initial: time.Hour, maxDelay: time.Minute}
+		if got := p.delay(100); got != time.Minute {
+			t.Fatalf("delay(100) = %v, want %v", got, time.Minute)
+		}
+	})
+
+	t.Run("overflow without max delay", func(t *testing.T) {
+		policies := map[Backoff][]int{
+			BackoffExponential: {31, 100, math.MaxInt32, math.MaxInt},
+			BackoffLinear:      {math.MaxInt32, math.MaxInt},
+		}
+		for backoff, attempts := range policies {
+			p := policy{backoff: backoff, initial: time.Hour}
+			for _, attempt := range attempts {
+				if got := p.delay(attempt); got != math.MaxInt64 {
+					t.Fatalf("%s delay(%d) = %v, want %v", p.backoff, attempt, got, time.Duration(math.MaxInt64))
+				}
+			}
+		}
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		p := policy{backoff: BackoffConstant, initial: 100 * time.Millisecond, jitter: true}
+		for range 100 {
+			if got := p.delay(1); got < 50*time.Millisecond || got >= 100*time.Millisecond {
+				t.Fatalf("delay(1) = %v, want between 50ms and 100ms", got)
+			}
+		}
+	})
+}
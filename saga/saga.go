@@ -0,0 +1,188 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package saga provides the compensation of the proxied method calls.
+// the generated proxy records the successful call of the method annotated with
+// @compensable(undo=Method) to the saga of the context. when the saga fails, the
+// compensation methods of the recorded calls are invoked in reverse order.
+//
+//	type FooBar interface {
+//		// @saga
+//		Place(c context.Context, order dto.Order) error
+//
+//		// @compensable(undo=CancelCreate)
+//		Create(c context.Context, foo dto.Foo) (int, error)
+//
+//		CancelCreate(c context.Context, foo dto.Foo) error
+//	}
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+const (
+	compensableAnnotation = "compensable"
+	undoArgument          = "undo"
+)
+
+var ErrCompensationFailed = errors.New("compensation failed")
+
+// Step is the successful call of the compensable method recorded in the saga.
+type Step struct {
+	// Invocation is the invocation of the compensable method.
+	Invocation *invocation.Invocation
+
+	// Undo is the name of the compensation method.
+	Undo string
+
+	compensate func(c context.Context) error
+}
+
+// String returns the step. e.g. FooBar.Create -> FooBar.CancelCreate
+func (s Step) String() string {
+	return fmt.Sprintf("%s -> %s.%s", s.Invocation, s.Invocation.Interface, s.Undo)
+}
+
+// DeadLetterHandler handles the step that failed to compensate.
+type DeadLetterHandler func(c context.Context, step Step, err error)
+
+// Option configures the saga.
+type Option func(*config)
+
+type config struct {
+	deadLetter DeadLetterHandler
+}
+
+// WithDeadLetter sets the handler of the failed compensations.
+// if no handler is set, the errors of the failed compensations are returned with the error of the saga.
+func WithDeadLetter(h DeadLetterHandler) Option {
+	return func(c *config) {
+		c.deadLetter = h
+	}
+}
+
+type logKey struct{}
+
+// log is the steps recorded in the saga.
+type log struct {
+	mu    sync.Mutex
+	steps []Step
+}
+
+func (l *log) record(step Step) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.steps = append(l.steps, step)
+}
+
+func (l *log) list() []Step {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.steps)
+}
+
+func fromContext(c context.Context) (*log, bool) {
+	l, ok := c.Value(logKey{}).(*log)
+	return l, ok && l != nil
+}
+
+// Active reports whether the context runs in the saga.
+func Active(c context.Context) bool {
+	_, ok := fromContext(c)
+	return ok
+}
+
+// Record records the successful call of the compensable method to the saga of the context.
+// it is called by the generated proxy. it reports whether the saga exists.
+func Record(c context.Context, inv *invocation.Invocation, compensate func(c context.Context) error) bool {
+	l, ok := fromContext(c)
+	if !ok {
+		return false
+	}
+
+	undo, _ := inv.Argument(compensableAnnotation, undoArgument)
+	l.record(Step{Invocation: inv, Undo: undo, compensate: compensate})
+	return true
+}
+
+// Run runs fn in the saga. if fn fails, the recorded steps are compensated in reverse order.
+// the saga in the saga records the steps to the outer saga when it succeeds,
+// and compensates only its own steps when it fails.
+func Run(c context.Context, fn func(c context.Context) error, opts ...Option) error {
+	config := config{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	l := &log{}
+	err := fn(context.WithValue(c, logKey{}, l))
+	if err == nil {
+		if parent, ok := fromContext(c); ok {
+			for _, step := range l.list() {
+				parent.record(step)
+			}
+		}
+		return nil
+	}
+	return errors.Join(err, compensate(c, l.list(), config))
+}
+
+// compensate invokes the compensations of the steps in reverse order.
+// the compensations run out of the saga and the cancellation of the context.
+func compensate(c context.Context, steps []Step, config config) error {
+	c = context.WithValue(context.WithoutCancel(c), logKey{}, (*log)(nil))
+
+	errs := []error{}
+	for _, step := range slices.Backward(steps) {
+		err := step.compensate(c)
+		if err == nil {
+			continue
+		}
+
+		if config.deadLetter != nil {
+			config.deadLetter(c, step, err)
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%w %s. %w", ErrCompensationFailed, step, err))
+	}
+	return errors.Join(errs...)
+}
+
+// Middleware returns a middleware of @saga.
+//
+//	"saga": {saga.Middleware(saga.WithDeadLetter(func(c context.Context, step saga.Step, err error) {
+//		slog.Error("compensation failed", "step", step.String(), "err", err)
+//	}))},
+func Middleware(opts ...Option) func(func(c context.Context) error) func(context.Context) error {
+	return func(next func(c context.Context) error) func(context.Context) error {
+		return func(c context.Context) error {
+			return Run(c, next, opts...)
+		}
+	}
+}
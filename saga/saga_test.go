@@ -0,0 +1,233 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package saga
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+var (
+	errFailed = errors.New("failed")
+	errUndo   = errors.New("undo failed")
+)
+
+// journal records the calls and the compensations of the steps.
+type journal struct {
+	calls []string
+}
+
+// step records the successful call of the method to the saga of the context.
+// the compensation of the step fails with undoErr.
+func (j *journal) step(c context.Context, method string, undoErr error) {
+	inv := &invocation.Invocation{
+		Interface: "FooBar",
+		Method:    method,
+		Annotations: []invocation.Annotation{
+			{Name: compensableAnnotation, Arguments: []invocation.Argument{{Key: undoArgument, Value: "Cancel" + method}}},
+		},
+	}
+
+	j.calls = append(j.calls, method)
+	Record(c, inv, func(c context.Context) error {
+		j.calls = append(j.calls, "Cancel"+method)
+		return undoErr
+	})
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      func(c context.Context, j *journal) error
+		calls   []string
+		wantErr []error
+	}{
+		{
+			name: "succeeds without compensation",
+			fn: func(c context.Context, j *journal) error {
+				j.step(c, "Create", nil)
+				j.step(c, "Reserve", nil)
+				return nil
+			},
+			calls: []string{"Create", "Reserve"},
+		},
+		{
+			name: "compensates in reverse order",
+			fn: func(c context.Context, j *journal) error {
+				j.step(c, "Create", nil)
+				j.step(c, "Reserve", nil)
+				return errFailed
+			},
+			calls:   []string{"Create", "Reserve", "CancelReserve", "CancelCreate"},
+			wantErr: []error{errFailed},
+		},
+		{
+			name: "continues after failed compensation",
+			fn: func(c context.Context, j *journal) error {
+				j.step(c, "Create", nil)
+				j.step(c, "Reserve", errUndo)
+				return errFailed
+			},
+			calls:   []string{"Create", "Reserve", "CancelReserve", "CancelCreate"},
+			wantErr: []error{errFailed, ErrCompensationFailed, errUndo},
+		},
+		{
+			name: "nested saga records to outer saga",
+			fn: func(c context.Context, j *journal) error {
+				j.step(c, "Create", nil)
+				if err := Run(c, func(c context.Context) error {
+					j.step(c, "Reserve", nil)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return errFailed
+			},
+			calls:   []string{"Create", "Reserve", "CancelReserve", "CancelCreate"},
+			wantErr: []error{errFailed},
+		},
+		{
+			name: "failed nested saga compensates own steps",
+			fn: func(c context.Context, j *journal) error {
+				j.step(c, "Create", nil)
+				_ = Run(c, func(c context.Context) error {
+					j.step(c, "Reserve", nil)
+					return errFailed
+				})
+				return nil
+			},
+			calls: []string{"Create", "Reserve", "CancelReserve"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &journal{}
+			err := Run(context.Background(), func(c context.Context) error {
+				return tt.fn(c, j)
+			})
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Fatalf("err = %v, want %v", err, want)
+				}
+			}
+
+			if !slices.Equal(j.calls, tt.calls) {
+				t.Fatalf("calls = %v, want %v", j.calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRunDeadLetter(t *testing.T) {
+	j := &journal{}
+	dead := []string{}
+	err := Run(context.Background(), func(c context.Context) error {
+		j.step(c, "Create", errUndo)
+		j.step(c, "Reserve", nil)
+		return errFailed
+	}, WithDeadLetter(func(c context.Context, step Step, err error) {
+		if !errors.Is(err, errUndo) {
+			t.Errorf("dead letter err = %v, want %v", err, errUndo)
+		}
+		dead = append(dead, step.String())
+	}))
+
+	if !errors.Is(err, errFailed) || errors.Is(err, ErrCompensationFailed) {
+		t.Fatalf("err = %v, want %v without %v", err, errFailed, ErrCompensationFailed)
+	}
+
+	if want := []string{"FooBar.Create -> FooBar.CancelCreate"}; !slices.Equal(dead, want) {
+		t.Fatalf("dead letters = %v, want %v", dead, want)
+	}
+}
+
+func TestCompensationContext(t *testing.T) {
+	c, cancel := context.WithCancel(context.Background())
+	inv := &invocation.Invocation{Interface: "FooBar", Method: "Create"}
+
+	var compensated context.Context
+	err := Run(c, func(c context.Context) error {
+		Record(c, inv, func(c context.Context) error {
+			compensated = c
+			return nil
+		})
+
+		cancel()
+		return c.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want %v", err, context.Canceled)
+	}
+
+	if compensated == nil {
+		t.Fatal("step is not compensated")
+	}
+
+	if compensated.Err() != nil {
+		t.Fatalf("compensation context is canceled: %v", compensated.Err())
+	}
+
+	if Active(compensated) {
+		t.Fatal("compensation runs in the saga")
+	}
+}
+
+func TestRecordOutOfSaga(t *testing.T) {
+	c := context.Background()
+	if Active(c) {
+		t.Fatal("Active() = true out of the saga")
+	}
+
+	if Record(c, &invocation.Invocation{}, func(context.Context) error { return nil }) {
+		t.Fatal("Record() = true out of the saga")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	j := &journal{}
+	m := Middleware()
+	err := m(func(c context.Context) error {
+		if !Active(c) {
+			return errors.New("saga is not active")
+		}
+
+		j.step(c, "Create", nil)
+		return errFailed
+	})(context.Background())
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("err = %v, want %v", err, errFailed)
+	}
+
+	if want := []string{"Create", "CancelCreate"}; !slices.Equal(j.calls, want) {
+		t.Fatalf("calls = %v, want %v", j.calls, want)
+	}
+}
@@ -0,0 +1,106 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package timeout provides the middleware of @timeout.
+// the middleware derives the deadline context that is passed to the target.
+//
+//	type Foo interface {
+//		// @timeout(800ms)
+//		Fetch(c context.Context, id int) (dto.Foo, error)
+//	}
+//
+//	m := map[string][]func(func(context.Context) error) func(context.Context) error{
+//		"timeout": {timeout.Middleware()},
+//	}
+package timeout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+const (
+	timeoutAnnotation = "timeout"
+	durationArgument  = "duration"
+)
+
+var (
+	// ErrTimeout is returned when the timeout of the method passes.
+	// the error also matches context.DeadlineExceeded.
+	ErrTimeout = errors.New("timed out")
+
+	ErrInvalidTimeout = errors.New("invalid timeout")
+)
+
+// Middleware returns a middleware of @timeout.
+// the target runs with the context that has the deadline of the timeout.
+// when the deadline passes, ErrTimeout and context.DeadlineExceeded wrapped with the method name are returned.
+// the cancellation and the deadline of the caller are not the timeout of the method, so the error of
+// the target is returned as it is. the middleware waits for the target to return, so the target should
+// respect the context.
+func Middleware() func(func(c context.Context) error) func(context.Context) error {
+	return func(next func(c context.Context) error) func(context.Context) error {
+		return func(c context.Context) error {
+			inv, _ := invocation.FromContext(c)
+			d, err := timeoutFromInvocation(inv)
+			if err != nil {
+				return err
+			}
+
+			// e.g. Foo.Fetch timed out after 800ms: context deadline exceeded
+			timeoutErr := fmt.Errorf("%s %w after %s: %w", inv, ErrTimeout, d, context.DeadlineExceeded)
+			c, cancel := context.WithTimeoutCause(c, d, timeoutErr)
+			defer cancel()
+
+			err = next(c)
+			if context.Cause(c) != timeoutErr {
+				return err
+			}
+
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				return errors.Join(timeoutErr, err)
+			}
+			return timeoutErr
+		}
+	}
+}
+
+func timeoutFromInvocation(inv *invocation.Invocation) (time.Duration, error) {
+	value, ok := inv.Argument(timeoutAnnotation, durationArgument)
+	if !ok || value == "" {
+		return 0, fmt.Errorf("%w. duration is not declared on %s", ErrInvalidTimeout, inv)
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if d <= 0 {
+		return 0, fmt.Errorf("%w. duration must be positive on %s", ErrInvalidTimeout, inv)
+	}
+	return d, nil
+}
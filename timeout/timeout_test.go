@@ -0,0 +1,153 @@
+// MIT License
+
+// Copyright (c) 2025 ISSuh
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package timeout
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ISSuh/gen-go-proxy/invocation"
+)
+
+var errFailed = errors.New("failed")
+
+// fetch returns the context of the call of Foo.Fetch annotated with @timeout(duration).
+func fetch(c context.Context, duration string) context.Context {
+	return invocation.WithContext(c, &invocation.Invocation{
+		Interface: "Foo",
+		Method:    "Fetch",
+		Annotations: []invocation.Annotation{
+			{Name: timeoutAnnotation, Arguments: []invocation.Argument{{Key: durationArgument, Value: duration}}},
+		},
+	})
+}
+
+// untilDone returns the target that returns err after the context is done.
+func untilDone(err error) func(c context.Context) error {
+	return func(c context.Context) error {
+		<-c.Done()
+		if err != nil {
+			return err
+		}
+		return c.Err()
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  func(c context.Context) error
+		wantErr []error
+		message string
+	}{
+		{
+			name:   "returns in time",
+			target: func(context.Context) error { return nil },
+		},
+		{
+			name:    "error in time",
+			target:  func(context.Context) error { return errFailed },
+			wantErr: []error{errFailed},
+		},
+		{
+			name:    "timed out",
+			target:  untilDone(nil),
+			wantErr: []error{ErrTimeout, context.DeadlineExceeded},
+			message: "Foo.Fetch timed out after 20ms: context deadline exceeded",
+		},
+		{
+			name:    "timed out with error of target",
+			target:  untilDone(errFailed),
+			wantErr: []error{ErrTimeout, context.DeadlineExceeded, errFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Middleware()(tt.target)(fetch(context.Background(), "20ms"))
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Fatalf("err = %v, want %v", err, want)
+				}
+			}
+
+			if tt.message != "" && err.Error() != tt.message {
+				t.Fatalf("message = %q, want %q", err.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestMiddlewareDeadline(t *testing.T) {
+	err := Middleware()(func(c context.Context) error {
+		deadline, ok := c.Deadline()
+		if !ok || time.Until(deadline) > time.Second {
+			return errors.New("deadline of the timeout is not set")
+		}
+		return nil
+	})(fetch(context.Background(), "1s"))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMiddlewareCaller(t *testing.T) {
+	t.Run("caller deadline", func(t *testing.T) {
+		c, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := Middleware()(untilDone(nil))(fetch(c, "1h"))
+		if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTimeout) {
+			t.Fatalf("err = %v, want %v without %v", err, context.DeadlineExceeded, ErrTimeout)
+		}
+	})
+
+	t.Run("caller cancellation", func(t *testing.T) {
+		c, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		err := Middleware()(untilDone(nil))(fetch(c, "1h"))
+		if !errors.Is(err, context.Canceled) || errors.Is(err, ErrTimeout) {
+			t.Fatalf("err = %v, want %v without %v", err, context.Canceled, ErrTimeout)
+		}
+	})
+}
+
+func TestMiddlewareInvalidTimeout(t *testing.T) {
+	for _, duration := range []string{"", "0s", "-1s"} {
+		called := false
+		err := Middleware()(func(context.Context) error {
+			called = true
+			return nil
+		})(fetch(context.Background(), duration))
+		if !errors.Is(err, ErrInvalidTimeout) || called {
+			t.Errorf("err of %q = %v, want %v without calling the target", duration, err, ErrInvalidTimeout)
+		}
+	}
+}